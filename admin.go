@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/utils"
+)
+
+// minAdminPasswordLength is the shortest password accepted for an admin account
+const minAdminPasswordLength = 8
+
+// runAdmin handles the `admin` subcommand
+func runAdmin(args []string) {
+	if len(args) == 0 || args[0] != "create" {
+		fmt.Fprintln(os.Stderr, "usage: bharat-seva admin create --email EMAIL --phone PHONE [--name NAME] [--password-stdin]")
+		os.Exit(2)
+	}
+	adminCreate(args[1:])
+}
+
+// adminCreate creates an admin account. The password is read from stdin with
+// --password-stdin; otherwise a random one is printed once and must be changed at first login.
+func adminCreate(args []string) {
+	flags := flag.NewFlagSet("admin create", flag.ExitOnError)
+	email := flags.String("email", "", "admin email address (required)")
+	phone := flags.String("phone", "", "admin phone number (required)")
+	name := flags.String("name", "Admin User", "admin display name")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from standard input")
+	flags.Parse(args)
+
+	if *email == "" || *phone == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var password string
+	if *passwordStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatal("Error reading password from stdin:", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+		if len(password) < minAdminPasswordLength {
+			log.Fatalf("Password must be at least %d characters", minAdminPasswordLength)
+		}
+	} else {
+		generated, err := utils.GenerateRandomPassword()
+		if err != nil {
+			log.Fatal("Error generating password:", err)
+		}
+		password = generated
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		log.Fatal("Error hashing password:", err)
+	}
+
+	connectDB()
+
+	adminUser, err := database.CreateAdmin(*email, *phone, *name, hashedPassword, !*passwordStdin)
+	if err != nil {
+		log.Fatal("Error creating admin user:", err)
+	}
+
+	fmt.Printf("Admin user created: %s (ID %d)\n", adminUser.Email, adminUser.ID)
+	if !*passwordStdin {
+		fmt.Printf("One-time password: %s\nIt must be changed at first login and will not be shown again.\n", password)
+	}
+}
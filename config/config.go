@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +13,15 @@ func LoadEnv() error {
 	return godotenv.Load("config.env")
 }
 
+// GetServerConfig returns server configuration
+func GetServerConfig() map[string]string {
+	return map[string]string{
+		"port":         os.Getenv("PORT"),
+		"env":          os.Getenv("ENV"),
+		"auto_migrate": os.Getenv("AUTO_MIGRATE"),
+	}
+}
+
 // GetDBConfig returns database configuration
 func GetDBConfig() map[string]string {
 	return map[string]string{
@@ -26,8 +36,39 @@ func GetDBConfig() map[string]string {
 // GetJWTConfig returns JWT configuration
 func GetJWTConfig() map[string]string {
 	return map[string]string{
-		"secret": os.Getenv("JWT_SECRET"),
-		"expiry": os.Getenv("JWT_EXPIRY"),
+		"secret":         os.Getenv("JWT_SECRET"),
+		"expiry":         os.Getenv("JWT_EXPIRY"),
+		"refresh_expiry": os.Getenv("REFRESH_TOKEN_EXPIRY"),
+	}
+}
+
+// GetAuthConfig returns authentication configuration
+func GetAuthConfig() map[string]string {
+	return map[string]string{
+		"user_cache_ttl":        os.Getenv("USER_CACHE_TTL"),
+		"password_reset_expiry": os.Getenv("PASSWORD_RESET_EXPIRY"),
+		"password_reset_url":    os.Getenv("PASSWORD_RESET_URL"),
+		"require_admin_2fa":     os.Getenv("REQUIRE_ADMIN_2FA"),
+		"totp_issuer":           os.Getenv("TOTP_ISSUER"),
+	}
+}
+
+// GetAdminBootstrapConfig returns the credentials used to create the first admin account
+func GetAdminBootstrapConfig() map[string]string {
+	return map[string]string{
+		"email":    os.Getenv("ADMIN_EMAIL"),
+		"phone":    os.Getenv("ADMIN_PHONE"),
+		"name":     os.Getenv("ADMIN_NAME"),
+		"password": os.Getenv("ADMIN_PASSWORD"),
+	}
+}
+
+// GetDownloadConfig returns signed download URL configuration
+func GetDownloadConfig() map[string]string {
+	return map[string]string{
+		"secret":   os.Getenv("DOWNLOAD_URL_SECRET"),
+		"expiry":   os.Getenv("DOWNLOAD_URL_EXPIRY"),
+		"base_url": strings.TrimRight(os.Getenv("PUBLIC_BASE_URL"), "/"),
 	}
 }
 
@@ -38,4 +79,83 @@ func GetUploadConfig() map[string]interface{} {
 		"upload_path":   os.Getenv("UPLOAD_PATH"),
 		"max_file_size": maxFileSize,
 	}
-} 
\ No newline at end of file
+}
+
+// GetStorageConfig returns document storage configuration
+func GetStorageConfig() map[string]string {
+	return map[string]string{
+		"backend":           os.Getenv("STORAGE_BACKEND"),
+		"s3_endpoint":       os.Getenv("S3_ENDPOINT"),
+		"s3_region":         os.Getenv("S3_REGION"),
+		"s3_bucket":         os.Getenv("S3_BUCKET"),
+		"s3_access_key":     os.Getenv("S3_ACCESS_KEY"),
+		"s3_secret_key":     os.Getenv("S3_SECRET_KEY"),
+		"s3_use_path_style": os.Getenv("S3_USE_PATH_STYLE"),
+	}
+}
+
+// GetMailConfig returns outgoing mail configuration
+func GetMailConfig() map[string]string {
+	return map[string]string{
+		"backend":       os.Getenv("MAIL_BACKEND"),
+		"from":          os.Getenv("MAIL_FROM"),
+		"log_path":      os.Getenv("MAIL_LOG_PATH"),
+		"smtp_host":     os.Getenv("SMTP_HOST"),
+		"smtp_port":     os.Getenv("SMTP_PORT"),
+		"smtp_username": os.Getenv("SMTP_USERNAME"),
+		"smtp_password": os.Getenv("SMTP_PASSWORD"),
+	}
+}
+
+// GetSMSConfig returns SMS configuration
+func GetSMSConfig() map[string]string {
+	return map[string]string{
+		"backend":  os.Getenv("SMS_BACKEND"),
+		"log_path": os.Getenv("SMS_LOG_PATH"),
+	}
+}
+
+// GetPaymentConfig returns payment gateway configuration
+func GetPaymentConfig() map[string]string {
+	return map[string]string{
+		"backend":        os.Getenv("PAYMENT_BACKEND"),
+		"gateway_url":    os.Getenv("PAYMENT_GATEWAY_URL"),
+		"key_id":         os.Getenv("PAYMENT_KEY_ID"),
+		"key_secret":     os.Getenv("PAYMENT_KEY_SECRET"),
+		"webhook_secret": os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+	}
+}
+
+// GetInvoiceConfig returns tax invoice configuration
+func GetInvoiceConfig() map[string]string {
+	return map[string]string{
+		"prefix":         os.Getenv("INVOICE_PREFIX"),
+		"seller_name":    os.Getenv("INVOICE_SELLER_NAME"),
+		"seller_address": os.Getenv("INVOICE_SELLER_ADDRESS"),
+		"seller_gstin":   os.Getenv("INVOICE_SELLER_GSTIN"),
+		"sac":            os.Getenv("INVOICE_SAC"),
+	}
+}
+
+// GetVerificationConfig returns OTP verification configuration
+func GetVerificationConfig() map[string]string {
+	return map[string]string{
+		"otp_expiry":               os.Getenv("OTP_EXPIRY"),
+		"otp_max_attempts":         os.Getenv("OTP_MAX_ATTEMPTS"),
+		"otp_resend_interval":      os.Getenv("OTP_RESEND_INTERVAL"),
+		"require_for_applications": os.Getenv("REQUIRE_VERIFICATION_FOR_APPLICATIONS"),
+		"otp_login_phone_limit":    os.Getenv("OTP_LOGIN_PHONE_LIMIT"),
+		"otp_login_ip_limit":       os.Getenv("OTP_LOGIN_IP_LIMIT"),
+	}
+}
+
+// GetLoginProtectionConfig returns brute-force protection configuration
+func GetLoginProtectionConfig() map[string]string {
+	return map[string]string{
+		"max_attempts":    os.Getenv("LOGIN_MAX_ATTEMPTS"),
+		"lockout_base":    os.Getenv("LOGIN_LOCKOUT_BASE"),
+		"lockout_max":     os.Getenv("LOGIN_LOCKOUT_MAX"),
+		"ip_max_failures": os.Getenv("LOGIN_IP_MAX_FAILURES"),
+		"ip_window":       os.Getenv("LOGIN_IP_WINDOW"),
+	}
+}
@@ -1,11 +1,13 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
 	"bharat-seva-space/config"
 	"bharat-seva-space/models"
+	"bharat-seva-space/utils"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -13,7 +15,7 @@ import (
 
 var DB *gorm.DB
 
-// InitDB initializes the database connection and runs migrations
+// InitDB initializes the database connection
 func InitDB() error {
 	dbConfig := config.GetDBConfig()
 	
@@ -31,47 +33,81 @@ func InitDB() error {
 		return fmt.Errorf("failed to connect to database: %v", err)
 	}
 
-	// Auto migrate the schema
-	if err := runMigrations(); err != nil {
-		return fmt.Errorf("failed to run migrations: %v", err)
-	}
-
-	log.Println("Database connected and migrations completed successfully")
+	log.Println("Database connected successfully")
 	return nil
 }
 
-// runMigrations runs database migrations
-func runMigrations() error {
-	return DB.AutoMigrate(
-		&models.User{},
-		&models.Query{},
-		&models.Application{},
-		&models.Document{},
-	)
+// ErrUserExists is returned when creating an account whose email or phone is already registered
+var ErrUserExists = errors.New("a user with this email or phone already exists")
+
+// CreateAdmin creates an active admin account with an already hashed password
+func CreateAdmin(email, phone, name, passwordHash string, mustChangePassword bool) (*models.User, error) {
+	var count int64
+	DB.Unscoped().Model(&models.User{}).Where("email = ? OR phone = ?", email, phone).Count(&count)
+	if count > 0 {
+		return nil, ErrUserExists
+	}
+
+	adminUser := models.User{
+		Email:              email,
+		Phone:              phone,
+		Name:               name,
+		Password:           passwordHash,
+		Role:               models.RoleAdmin,
+		IsActive:           true,
+		MustChangePassword: mustChangePassword,
+	}
+
+	if err := DB.Create(&adminUser).Error; err != nil {
+		return nil, fmt.Errorf("failed to create admin user: %v", err)
+	}
+
+	return &adminUser, nil
 }
 
-// CreateAdminUser creates a default admin user if it doesn't exist
-func CreateAdminUser() error {
+// BootstrapAdmin creates the first admin account from ADMIN_* settings when no
+// admin exists yet. Without ADMIN_PASSWORD a random password is generated, printed
+// once, and must be changed at first login.
+func BootstrapAdmin() error {
 	var count int64
-	DB.Model(&models.User{}).Where("role = ?", "admin").Count(&count)
-	
-	if count == 0 {
-		// Create default admin user
-		adminUser := models.User{
-			Email:    "admin@bharatseva.com",
-			Phone:    "9999999999",
-			Name:     "Admin User",
-			Password: "$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi", // password
-			Role:     "admin",
-			IsActive: true,
-		}
-		
-		if err := DB.Create(&adminUser).Error; err != nil {
-			return fmt.Errorf("failed to create admin user: %v", err)
+	DB.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count)
+	if count > 0 {
+		return nil
+	}
+
+	adminConfig := config.GetAdminBootstrapConfig()
+	if adminConfig["email"] == "" || adminConfig["phone"] == "" {
+		log.Println("No admin account exists. Set ADMIN_EMAIL and ADMIN_PHONE, or run `bharat-seva admin create`, to create one.")
+		return nil
+	}
+
+	name := adminConfig["name"]
+	if name == "" {
+		name = "Admin User"
+	}
+
+	password := adminConfig["password"]
+	generated := password == ""
+	if generated {
+		var err error
+		if password, err = utils.GenerateRandomPassword(); err != nil {
+			return fmt.Errorf("failed to generate admin password: %v", err)
 		}
-		
-		log.Println("Default admin user created: admin@bharatseva.com / password")
 	}
-	
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash admin password: %v", err)
+	}
+
+	if _, err := CreateAdmin(adminConfig["email"], adminConfig["phone"], name, hashedPassword, generated); err != nil {
+		return err
+	}
+
+	log.Printf("Admin user created: %s", adminConfig["email"])
+	if generated {
+		// Printed to stdout only, and only this once; the account must change it at first login
+		fmt.Printf("\n  One-time admin password for %s: %s\n  It must be changed at first login and will not be shown again.\n\n", adminConfig["email"], password)
+	}
 	return nil
-} 
\ No newline at end of file
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles holds the versioned SQL migrations. Each version has a
+// NNNN_name.up.sql file and a matching NNNN_name.down.sql file.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration records an applied migration in the schema_migrations table
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// loadMigrations reads the embedded migrations, ordered by version
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// ensureMigrationsTable creates the schema_migrations table if needed
+func ensureMigrationsTable() error {
+	return DB.Exec(`CREATE TABLE IF NOT EXISTS "schema_migrations" (
+		"version" bigint PRIMARY KEY,
+		"name" text NOT NULL,
+		"applied_at" timestamptz NOT NULL
+	)`).Error
+}
+
+// appliedMigrations returns the applied migrations keyed by version
+func appliedMigrations() (map[int64]SchemaMigration, error) {
+	applied := map[int64]SchemaMigration{}
+	if !DB.Migrator().HasTable(&SchemaMigration{}) {
+		return applied, nil
+	}
+
+	var rows []SchemaMigration
+	if err := DB.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Migrate applies all pending migrations in order, each in its own transaction
+func Migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := ensureMigrationsTable(); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %v", err)
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %v", err)
+	}
+
+	count := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d_%s failed: %v", m.Version, m.Name, err)
+		}
+
+		log.Printf("Applied migration %d_%s", m.Version, m.Name)
+		count++
+	}
+
+	if count == 0 {
+		log.Println("Database schema is up to date")
+	}
+	return nil
+}
+
+// Rollback reverts the most recently applied migrations, newest first
+func Rollback(steps int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %v", err)
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(m.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %d_%s failed: %v", m.Version, m.Name, err)
+		}
+
+		log.Printf("Rolled back migration %d_%s", m.Version, m.Name)
+		steps--
+	}
+
+	return nil
+}
+
+// GetMigrationStatus lists every known migration and when it was applied
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %v", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// CheckSchema returns an error if any migration has not been applied yet
+func CheckSchema() error {
+	statuses, err := GetMigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema is behind: %d pending migration(s), run `bharat-seva migrate up`", pending)
+	}
+	return nil
+}
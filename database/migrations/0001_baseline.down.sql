@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "documents";
+DROP TABLE IF EXISTS "applications";
+DROP TABLE IF EXISTS "queries";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,75 @@
+-- Baseline schema, matching the tables GORM AutoMigrate created before versioned
+-- migrations. Uses IF NOT EXISTS so databases created by AutoMigrate can adopt
+-- versioned migrations; later migrations do the same for what they add.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "email" text NOT NULL,
+    "phone" text NOT NULL,
+    "name" text NOT NULL,
+    "password" text NOT NULL,
+    "role" text DEFAULT 'user',
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_phone" ON "users" ("phone");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "queries" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "email" text NOT NULL,
+    "phone" text NOT NULL,
+    "service" text NOT NULL,
+    "message" text,
+    "status" text DEFAULT 'new',
+    "assigned_to" bigint,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_queries_assigned_user" FOREIGN KEY ("assigned_to") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_queries_deleted_at" ON "queries" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "applications" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "service_type" text NOT NULL,
+    "status" text DEFAULT 'pending',
+    "progress" text DEFAULT '0%',
+    "payment_status" text DEFAULT 'pending',
+    "amount" decimal DEFAULT 0,
+    "description" text,
+    "assigned_ca" bigint,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_applications" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_applications_assigned_ca_user" FOREIGN KEY ("assigned_ca") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_applications_deleted_at" ON "applications" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "documents" (
+    "id" bigserial,
+    "application_id" bigint NOT NULL,
+    "file_name" text NOT NULL,
+    "file_path" text NOT NULL,
+    "file_size" bigint,
+    "file_type" text,
+    "description" text,
+    "uploaded_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_applications_documents" FOREIGN KEY ("application_id") REFERENCES "applications"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_documents_deleted_at" ON "documents" ("deleted_at");
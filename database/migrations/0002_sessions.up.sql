@@ -0,0 +1,18 @@
+-- Refresh token sessions.
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "token_hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "revoked_at" timestamptz,
+    "replaced_by_id" bigint,
+    "user_agent" text,
+    "ip_address" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sessions_token_hash" ON "sessions" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions" ("user_id");
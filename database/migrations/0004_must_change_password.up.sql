@@ -0,0 +1,3 @@
+-- Accounts that must set a new password before using the API.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "must_change_password" boolean DEFAULT false;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "otps";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "phone_verified_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
@@ -0,0 +1,19 @@
+-- Email and phone verification with one-time codes.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "phone_verified_at" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "otps" (
+    "id" bigserial,
+    "user_id" bigint,
+    "purpose" text NOT NULL,
+    "destination" text NOT NULL,
+    "code_hash" text NOT NULL,
+    "attempts" bigint DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "consumed_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_otp_destination" ON "otps" ("purpose","destination");
+CREATE INDEX IF NOT EXISTS "idx_otps_user_id" ON "otps" ("user_id");
@@ -0,0 +1,19 @@
+-- Login attempt log and account lockout.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "failed_login_attempts" bigint DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "id" bigserial,
+    "user_id" bigint,
+    "email" text,
+    "ip_address" text,
+    "success" boolean,
+    "reason" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_created_at" ON "login_attempts" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_ip_address" ON "login_attempts" ("ip_address");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_email" ON "login_attempts" ("email");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_user_id" ON "login_attempts" ("user_id");
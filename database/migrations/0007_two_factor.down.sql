@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "recovery_codes";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_last_used_step";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_pending_secret";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_secret";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "totp_enabled";
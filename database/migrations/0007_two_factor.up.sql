@@ -0,0 +1,17 @@
+-- TOTP two-factor authentication with recovery codes.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_enabled" boolean DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_secret" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_pending_secret" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_last_used_step" bigint;
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "code_hash" text NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_code_hash" ON "recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
@@ -0,0 +1,3 @@
+UPDATE "users" SET "role" = 'user' WHERE "role" NOT IN ('admin', 'user');
+
+DROP TABLE IF EXISTS "roles";
@@ -0,0 +1,23 @@
+-- Roles with permission sets. users.role holds the role name.
+
+CREATE TABLE "roles" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "description" text,
+    "permissions" text NOT NULL DEFAULT '[]',
+    "is_system" boolean DEFAULT false,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+
+INSERT INTO "roles" ("name", "description", "permissions", "is_system", "created_at", "updated_at") VALUES
+    ('admin', 'Full access to every admin API', '["*"]', true, now(), now()),
+    ('user', 'Registered customer', '[]', true, now(), now()),
+    ('ca', 'Chartered accountant assigned to applications', '[]', true, now(), now()),
+    ('support', 'Support staff handling queries', '["queries:read","queries:update","users:read","applications:read"]', true, now(), now()),
+    ('finance', 'Finance staff handling payments', '["applications:read","payments:read","payments:refund"]', true, now(), now());
+
+-- Users with a role that no longer exists fall back to a regular user
+UPDATE "users" SET "role" = 'user' WHERE "role" IS NULL OR "role" NOT IN (SELECT "name" FROM "roles");
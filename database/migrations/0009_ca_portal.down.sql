@@ -0,0 +1,7 @@
+ALTER TABLE "documents" DROP COLUMN IF EXISTS "uploaded_by";
+ALTER TABLE "documents" DROP COLUMN IF EXISTS "kind";
+
+DROP INDEX IF EXISTS "idx_applications_assigned_ca";
+
+UPDATE "roles" SET "permissions" = '[]', "updated_at" = now()
+    WHERE "name" = 'ca' AND "permissions" = '["applications:assigned"]';
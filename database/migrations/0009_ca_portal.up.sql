@@ -0,0 +1,9 @@
+-- CA portal: CAs work on applications assigned to them and upload deliverables.
+
+UPDATE "roles" SET "permissions" = '["applications:assigned"]', "updated_at" = now()
+    WHERE "name" = 'ca' AND "permissions" = '[]';
+
+CREATE INDEX IF NOT EXISTS "idx_applications_assigned_ca" ON "applications" ("assigned_ca");
+
+ALTER TABLE "documents" ADD COLUMN "kind" text NOT NULL DEFAULT 'upload';
+ALTER TABLE "documents" ADD COLUMN "uploaded_by" bigint;
@@ -0,0 +1,16 @@
+-- Timeline of changes to tracked application fields.
+
+CREATE TABLE "application_events" (
+    "id" bigserial,
+    "application_id" bigint NOT NULL,
+    "actor_id" bigint,
+    "field" text NOT NULL,
+    "old_value" text,
+    "new_value" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_applications_events" FOREIGN KEY ("application_id") REFERENCES "applications"("id"),
+    CONSTRAINT "fk_application_events_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_application_events_application_id" ON "application_events" ("application_id");
+CREATE INDEX IF NOT EXISTS "idx_application_events_created_at" ON "application_events" ("created_at");
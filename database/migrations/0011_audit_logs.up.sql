@@ -0,0 +1,22 @@
+-- Audit log of mutating admin requests, readable with the audit:read permission.
+
+CREATE TABLE "audit_logs" (
+    "id" bigserial,
+    "actor_id" bigint NOT NULL,
+    "actor_email" text,
+    "method" text NOT NULL,
+    "route" text NOT NULL,
+    "path" text NOT NULL,
+    "entity_type" text,
+    "entity_id" text,
+    "changes" text,
+    "request" text,
+    "status_code" bigint,
+    "ip_address" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity_type" ON "audit_logs" ("entity_type");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity_id" ON "audit_logs" ("entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
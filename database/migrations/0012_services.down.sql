@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "idx_applications_service_id";
+ALTER TABLE "applications" DROP CONSTRAINT IF EXISTS "fk_applications_service";
+ALTER TABLE "applications" DROP COLUMN IF EXISTS "gst_rate";
+ALTER TABLE "applications" DROP COLUMN IF EXISTS "service_id";
+
+DROP TABLE IF EXISTS "services";
@@ -0,0 +1,33 @@
+-- Service catalog. Applications are priced from it and keep the GST rate they were created with.
+
+CREATE TABLE "services" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "slug" text NOT NULL,
+    "description" text,
+    "base_price" decimal NOT NULL DEFAULT 0,
+    "gst_rate" decimal NOT NULL DEFAULT 18,
+    "required_documents" text NOT NULL DEFAULT '[]',
+    "sla_days" bigint DEFAULT 0,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_services_name" ON "services" ("name");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_services_slug" ON "services" ("slug");
+
+INSERT INTO "services" ("name", "slug", "description", "base_price", "gst_rate", "required_documents", "sla_days", "is_active", "created_at", "updated_at") VALUES
+    ('GST Registration', 'gst-registration', 'New GST registration for proprietorships, partnerships and companies', 1999, 18, '["PAN card","Aadhaar card","Address proof of business","Bank statement or cancelled cheque","Photograph"]', 7, true, now(), now()),
+    ('Income Tax Filing', 'income-tax-filing', 'Income tax return filing for individuals', 999, 18, '["PAN card","Aadhaar card","Form 16","Bank statements"]', 3, true, now(), now()),
+    ('Company Registration', 'company-registration', 'Private limited company incorporation', 6999, 18, '["PAN card of directors","Aadhaar card of directors","Address proof of registered office","Photographs of directors"]', 15, true, now(), now()),
+    ('TDS Return Filing', 'tds-return-filing', 'Quarterly TDS return filing', 1499, 18, '["TAN","Challan details","Deductee details"]', 5, true, now(), now());
+
+ALTER TABLE "applications" ADD COLUMN "service_id" bigint;
+ALTER TABLE "applications" ADD COLUMN "gst_rate" decimal DEFAULT 0;
+ALTER TABLE "applications" ADD CONSTRAINT "fk_applications_service" FOREIGN KEY ("service_id") REFERENCES "services"("id");
+CREATE INDEX IF NOT EXISTS "idx_applications_service_id" ON "applications" ("service_id");
+
+-- Link existing applications to the catalog entry with the same name
+UPDATE "applications" SET "service_id" = "services"."id"
+    FROM "services" WHERE LOWER("applications"."service_type") = LOWER("services"."name");
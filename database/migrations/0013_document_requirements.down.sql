@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS "idx_documents_requirement_id";
+ALTER TABLE "documents" DROP COLUMN IF EXISTS "requirement_id";
+
+ALTER TABLE "services" ADD COLUMN "required_documents" text NOT NULL DEFAULT '[]';
+UPDATE "services" SET "required_documents" = COALESCE((
+    SELECT json_agg("name" ORDER BY "sort_order", "id")::text
+    FROM "document_requirements" WHERE "document_requirements"."service_id" = "services"."id"
+), '[]');
+
+DROP TABLE IF EXISTS "document_requirements";
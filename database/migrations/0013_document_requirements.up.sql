@@ -0,0 +1,29 @@
+-- Per-service document requirements replace services.required_documents, and
+-- uploaded documents can be tagged with the requirement they satisfy.
+
+CREATE TABLE "document_requirements" (
+    "id" bigserial,
+    "service_id" bigint NOT NULL,
+    "key" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text,
+    "optional" boolean DEFAULT false,
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_services_document_requirements" FOREIGN KEY ("service_id") REFERENCES "services"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_document_requirements_service_key" ON "document_requirements" ("service_id", "key");
+
+INSERT INTO "document_requirements" ("service_id", "key", "name", "description", "optional", "sort_order", "created_at", "updated_at")
+SELECT "services"."id",
+       TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE("doc"."name", '[^a-zA-Z0-9]+', '-', 'g'))),
+       "doc"."name", '', false, "doc"."ordinality" - 1, now(), now()
+FROM "services", json_array_elements_text("services"."required_documents"::json) WITH ORDINALITY AS "doc"("name", "ordinality")
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "services" DROP COLUMN "required_documents";
+
+ALTER TABLE "documents" ADD COLUMN "requirement_id" bigint;
+CREATE INDEX IF NOT EXISTS "idx_documents_requirement_id" ON "documents" ("requirement_id");
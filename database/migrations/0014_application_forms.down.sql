@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_applications_form_data";
+ALTER TABLE "applications" DROP COLUMN IF EXISTS "form_data";
+
+ALTER TABLE "services" DROP COLUMN IF EXISTS "form_schema";
@@ -0,0 +1,12 @@
+-- Services can define a form schema, and applications store the submitted answers.
+
+ALTER TABLE "services" ADD COLUMN "form_schema" text;
+
+ALTER TABLE "applications" ADD COLUMN "form_data" jsonb;
+CREATE INDEX IF NOT EXISTS "idx_applications_form_data" ON "applications" USING gin("form_data");
+
+UPDATE "services" SET "form_schema" = '{"type":"object","properties":{"pan":{"type":"string","title":"PAN","pattern":"^[A-Z]{5}[0-9]{4}[A-Z]$","x-filterable":true},"business_name":{"type":"string","title":"Business name","minLength":2,"maxLength":200,"x-filterable":true},"constitution":{"type":"string","title":"Constitution of business","enum":["proprietorship","partnership","llp","private_limited","public_limited"],"x-filterable":true},"state":{"type":"string","title":"State","x-filterable":true},"annual_turnover":{"type":"number","title":"Expected annual turnover","minimum":0}},"required":["pan","business_name","constitution","state"]}'
+WHERE "slug" = 'gst-registration';
+
+UPDATE "services" SET "form_schema" = '{"type":"object","properties":{"pan":{"type":"string","title":"PAN","pattern":"^[A-Z]{5}[0-9]{4}[A-Z]$","x-filterable":true},"assessment_year":{"type":"string","title":"Assessment year","pattern":"^[0-9]{4}-[0-9]{2}$","x-filterable":true},"income_sources":{"type":"string","title":"Sources of income","description":"Salary, house property, business, capital gains or other sources"},"has_foreign_income":{"type":"boolean","title":"Foreign income or assets"}},"required":["pan","assessment_year"]}'
+WHERE "slug" = 'income-tax-filing';
@@ -0,0 +1,34 @@
+-- Gateway payment orders for applications, and the webhook deliveries that settle them.
+
+CREATE TABLE "payments" (
+    "id" bigserial,
+    "application_id" bigint NOT NULL,
+    "gateway" text NOT NULL,
+    "order_id" text NOT NULL,
+    "gateway_payment_id" text,
+    "amount" decimal NOT NULL,
+    "currency" text NOT NULL DEFAULT 'INR',
+    "status" text NOT NULL DEFAULT 'created',
+    "method" text,
+    "failure_reason" text,
+    "paid_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_payments_application" FOREIGN KEY ("application_id") REFERENCES "applications"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_payments_order_id" ON "payments" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_gateway_payment_id" ON "payments" ("gateway_payment_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_application_id" ON "payments" ("application_id");
+
+CREATE TABLE "payment_webhook_events" (
+    "id" bigserial,
+    "event_id" text NOT NULL,
+    "event" text NOT NULL,
+    "order_id" text,
+    "payload" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_payment_webhook_events_event_id" ON "payment_webhook_events" ("event_id");
+CREATE INDEX IF NOT EXISTS "idx_payment_webhook_events_order_id" ON "payment_webhook_events" ("order_id");
@@ -0,0 +1,30 @@
+-- Refund requests against paid payments, with finance review and gateway outcome.
+
+ALTER TABLE "payments" ADD COLUMN "refunded_amount" decimal NOT NULL DEFAULT 0;
+
+CREATE TABLE "refunds" (
+    "id" bigserial,
+    "payment_id" bigint NOT NULL,
+    "application_id" bigint NOT NULL,
+    "amount" decimal NOT NULL,
+    "reason" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'requested',
+    "requested_by" bigint NOT NULL,
+    "reviewed_by" bigint,
+    "review_note" text,
+    "reviewed_at" timestamptz,
+    "gateway_refund_id" text,
+    "failure_reason" text,
+    "processed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_refunds_payment" FOREIGN KEY ("payment_id") REFERENCES "payments"("id"),
+    CONSTRAINT "fk_refunds_application" FOREIGN KEY ("application_id") REFERENCES "applications"("id"),
+    CONSTRAINT "fk_refunds_requested_by_user" FOREIGN KEY ("requested_by") REFERENCES "users"("id"),
+    CONSTRAINT "fk_refunds_reviewed_by_user" FOREIGN KEY ("reviewed_by") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_refunds_payment_id" ON "refunds" ("payment_id");
+CREATE INDEX IF NOT EXISTS "idx_refunds_application_id" ON "refunds" ("application_id");
+CREATE INDEX IF NOT EXISTS "idx_refunds_status" ON "refunds" ("status");
+CREATE INDEX IF NOT EXISTS "idx_refunds_gateway_refund_id" ON "refunds" ("gateway_refund_id");
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "invoices";
+DROP TABLE IF EXISTS "invoice_sequences";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "gstin";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "state";
@@ -0,0 +1,44 @@
+-- GST tax invoices for paid applications, numbered per financial year, and the
+-- customer details that decide how GST is split.
+
+ALTER TABLE "users" ADD COLUMN "state" text;
+ALTER TABLE "users" ADD COLUMN "gstin" text;
+
+CREATE TABLE "invoice_sequences" (
+    "financial_year" text,
+    "last_number" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("financial_year")
+);
+
+CREATE TABLE "invoices" (
+    "id" bigserial,
+    "number" text NOT NULL,
+    "financial_year" text NOT NULL,
+    "sequence" bigint NOT NULL,
+    "application_id" bigint NOT NULL,
+    "document_id" bigint NOT NULL,
+    "issued_at" timestamptz NOT NULL,
+    "seller_name" text NOT NULL,
+    "seller_address" text,
+    "seller_gstin" text NOT NULL,
+    "seller_state" text NOT NULL,
+    "customer_name" text NOT NULL,
+    "customer_email" text,
+    "customer_gstin" text,
+    "place_of_supply" text NOT NULL,
+    "supply_type" text NOT NULL,
+    "description" text NOT NULL,
+    "sac" text,
+    "taxable_amount" decimal NOT NULL,
+    "gst_rate" decimal NOT NULL,
+    "cgst" decimal NOT NULL DEFAULT 0,
+    "sgst" decimal NOT NULL DEFAULT 0,
+    "igst" decimal NOT NULL DEFAULT 0,
+    "total" decimal NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_invoices_application" FOREIGN KEY ("application_id") REFERENCES "applications"("id"),
+    CONSTRAINT "fk_invoices_document" FOREIGN KEY ("document_id") REFERENCES "documents"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_number" ON "invoices" ("number");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_application_id" ON "invoices" ("application_id");
@@ -0,0 +1,2 @@
+UPDATE "roles" SET "permissions" = '["applications:read","payments:read","payments:refund"]', "updated_at" = now()
+    WHERE "name" = 'finance' AND "permissions" = '["applications:read","payments:read","payments:refund","invoices:issue"]';
@@ -0,0 +1,4 @@
+-- Issuing invoices by hand gets its own permission, granted to finance staff.
+
+UPDATE "roles" SET "permissions" = '["applications:read","payments:read","payments:refund","invoices:issue"]', "updated_at" = now()
+    WHERE "name" = 'finance' AND "permissions" = '["applications:read","payments:read","payments:refund"]';
@@ -46,8 +46,9 @@ echo -e "${YELLOW}Next steps:${NC}"
 echo -e "1. Create RDS PostgreSQL instance"
 echo -e "2. Create ECS Cluster"
 echo -e "3. Create ECS Task Definition"
-echo -e "4. Create ECS Service"
-echo -e "5. Configure Application Load Balancer"
+echo -e "4. Run migrations as a one-off task: aws ecs run-task ... --overrides '{\"containerOverrides\":[{\"name\":\"bharat-seva-app\",\"command\":[\"./bharat-seva\",\"migrate\",\"up\"]}]}'"
+echo -e "5. Create ECS Service"
+echo -e "6. Configure Application Load Balancer"
 echo -e ""
 echo -e "Run the following command to continue:"
 echo -e "aws ecs create-cluster --cluster-name $ECS_CLUSTER_NAME --region $AWS_REGION" 
\ No newline at end of file
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"gorm.io/gorm"
+)
+
+// exportTable is a set of exported records with named columns
+type exportTable struct {
+	columns []string
+	rows    [][]interface{}
+}
+
+// exporters load each exportable entity from a query already ordered and filtered by the caller
+var exporters = map[string]func(db *gorm.DB) (exportTable, error){
+	"users":        exportUsers,
+	"queries":      exportQueries,
+	"applications": exportApplications,
+	"audit":        exportAuditLogs,
+}
+
+// runExport handles the `export` subcommand
+func runExport(args []string) {
+	if len(args) == 0 || exporters[args[0]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: bharat-seva export users|queries|applications|audit [--format csv|json] [--output FILE] [--since YYYY-MM-DD]")
+		os.Exit(2)
+	}
+	entity := args[0]
+
+	flags := flag.NewFlagSet("export "+entity, flag.ExitOnError)
+	format := flags.String("format", "csv", "output format: csv or json")
+	output := flags.String("output", "", "write to this file instead of stdout")
+	since := flags.String("since", "", "only export records created on or after this date (YYYY-MM-DD)")
+	flags.Parse(args[1:])
+
+	if *format != "csv" && *format != "json" {
+		log.Fatal("Format must be csv or json")
+	}
+
+	connectDB()
+
+	db := database.DB.Order("id")
+	if *since != "" {
+		sinceDate, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			log.Fatal("Invalid --since date, expected YYYY-MM-DD")
+		}
+		db = db.Where("created_at >= ?", sinceDate)
+	}
+
+	table, err := exporters[entity](db)
+	if err != nil {
+		log.Fatalf("Error exporting %s: %v", entity, err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal("Error creating output file:", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "json" {
+		err = writeExportJSON(w, table)
+	} else {
+		err = writeExportCSV(w, table)
+	}
+	if err != nil {
+		log.Fatalf("Error writing %s: %v", entity, err)
+	}
+
+	if *output != "" {
+		log.Printf("Exported %d %s to %s", len(table.rows), entity, *output)
+	}
+}
+
+// exportValue converts a field to a plain value, flattening optional fields and times
+func exportValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return value.Format(time.RFC3339)
+	case *uint:
+		if value == nil {
+			return nil
+		}
+		return *value
+	}
+	return v
+}
+
+// writeExportCSV writes a table as CSV with a header row
+func writeExportCSV(w io.Writer, table exportTable) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.columns); err != nil {
+		return err
+	}
+	for _, row := range table.rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			if value := exportValue(v); value != nil {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeExportJSON writes a table as a JSON array of objects
+func writeExportJSON(w io.Writer, table exportTable) error {
+	records := make([]map[string]interface{}, 0, len(table.rows))
+	for _, row := range table.rows {
+		record := make(map[string]interface{}, len(row))
+		for i, v := range row {
+			record[table.columns[i]] = exportValue(v)
+		}
+		records = append(records, record)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// exportUsers exports user accounts without credentials or secrets
+func exportUsers(db *gorm.DB) (exportTable, error) {
+	var users []models.User
+	if err := db.Find(&users).Error; err != nil {
+		return exportTable{}, err
+	}
+
+	table := exportTable{columns: []string{"id", "email", "phone", "name", "role", "is_active", "email_verified_at", "phone_verified_at", "two_factor_enabled", "created_at"}}
+	for _, user := range users {
+		table.rows = append(table.rows, []interface{}{
+			user.ID, user.Email, user.Phone, user.Name, user.Role, user.IsActive,
+			user.EmailVerifiedAt, user.PhoneVerifiedAt, user.TOTPEnabled, user.CreatedAt,
+		})
+	}
+	return table, nil
+}
+
+// exportQueries exports website queries
+func exportQueries(db *gorm.DB) (exportTable, error) {
+	var queries []models.Query
+	if err := db.Find(&queries).Error; err != nil {
+		return exportTable{}, err
+	}
+
+	table := exportTable{columns: []string{"id", "name", "email", "phone", "service", "message", "status", "assigned_to", "notes", "created_at"}}
+	for _, query := range queries {
+		table.rows = append(table.rows, []interface{}{
+			query.ID, query.Name, query.Email, query.Phone, query.Service, query.Message,
+			query.Status, query.AssignedTo, query.Notes, query.CreatedAt,
+		})
+	}
+	return table, nil
+}
+
+// exportApplications exports applications with the applicant's email
+func exportApplications(db *gorm.DB) (exportTable, error) {
+	var applications []models.Application
+	if err := db.Preload("User").Find(&applications).Error; err != nil {
+		return exportTable{}, err
+	}
+
+	table := exportTable{columns: []string{"id", "user_id", "user_email", "service_type", "status", "progress", "payment_status", "amount", "description", "form_data", "assigned_ca", "created_at", "updated_at"}}
+	for _, application := range applications {
+		formData := ""
+		if len(application.FormData) > 0 {
+			encoded, err := json.Marshal(application.FormData)
+			if err != nil {
+				return exportTable{}, err
+			}
+			formData = string(encoded)
+		}
+		table.rows = append(table.rows, []interface{}{
+			application.ID, application.UserID, application.User.Email, application.ServiceType,
+			application.Status, application.Progress, application.PaymentStatus, application.Amount,
+			application.Description, formData, application.AssignedCA, application.CreatedAt, application.UpdatedAt,
+		})
+	}
+	return table, nil
+}
+
+// exportAuditLogs exports the admin audit log
+func exportAuditLogs(db *gorm.DB) (exportTable, error) {
+	var logs []models.AuditLog
+	if err := db.Find(&logs).Error; err != nil {
+		return exportTable{}, err
+	}
+
+	table := exportTable{columns: []string{"id", "created_at", "actor_id", "actor_email", "method", "route", "path", "entity_type", "entity_id", "status_code", "ip_address", "changes", "request"}}
+	for _, entry := range logs {
+		changes := ""
+		if len(entry.Changes) > 0 {
+			encoded, err := json.Marshal(entry.Changes)
+			if err != nil {
+				return exportTable{}, err
+			}
+			changes = string(encoded)
+		}
+		table.rows = append(table.rows, []interface{}{
+			entry.ID, entry.CreatedAt, entry.ActorID, entry.ActorEmail, entry.Method, entry.Route, entry.Path,
+			entry.EntityType, entry.EntityID, entry.StatusCode, entry.IPAddress, changes, entry.Request,
+		})
+	}
+	return table, nil
+}
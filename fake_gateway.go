@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/payments"
+)
+
+// runFakeGateway serves the fake payment gateway used by PAYMENT_BACKEND=fake
+func runFakeGateway(args []string) {
+	paymentConfig := config.GetPaymentConfig()
+
+	webhookURL := "http://localhost:8080/api/payments/webhook"
+	if baseURL := config.GetDownloadConfig()["base_url"]; baseURL != "" {
+		webhookURL = baseURL + "/api/payments/webhook"
+	}
+
+	flags := flag.NewFlagSet("fake-gateway", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:9090", "address to listen on")
+	webhook := flags.String("webhook-url", webhookURL, "URL webhooks are delivered to")
+	flags.Parse(args)
+
+	if config.GetServerConfig()["env"] == "production" {
+		log.Fatal("Refusing to run the fake payment gateway with ENV=production")
+	}
+	if paymentConfig["key_id"] == "" || paymentConfig["key_secret"] == "" || paymentConfig["webhook_secret"] == "" {
+		log.Fatal("PAYMENT_KEY_ID, PAYMENT_KEY_SECRET and PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
+	gateway := payments.NewFakeGateway(paymentConfig["key_id"], paymentConfig["key_secret"], paymentConfig["webhook_secret"], *webhook)
+
+	log.Printf("Fake payment gateway listening on %s, sending webhooks to %s", *addr, *webhook)
+	if err := http.ListenAndServe(*addr, gateway.Handler()); err != nil {
+		log.Fatal("Error starting fake payment gateway:", err)
+	}
+}
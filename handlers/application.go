@@ -1,38 +1,136 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"bharat-seva-space/config"
 	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
 	"bharat-seva-space/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// errApplicationConflict is returned when a guarded update finds the application already changed
+var errApplicationConflict = errors.New("application changed concurrently")
+
+// applicationUserResponse converts a user related to an application to its API response
+func applicationUserResponse(user models.User) models.UserResponse {
+	return models.UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		Name:      user.Name,
+		Role:      user.Role,
+		IsActive:  user.IsActive,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// applicationResponse converts an application, with whichever relationships were
+// preloaded, to its API response
+func applicationResponse(app models.Application) models.ApplicationResponse {
+	response := models.ApplicationResponse{
+		ID:            app.ID,
+		UserID:        app.UserID,
+		ServiceID:     app.ServiceID,
+		ServiceType:   app.ServiceType,
+		Status:        app.Status,
+		Progress:      app.Progress,
+		PaymentStatus: app.PaymentStatus,
+		Amount:        app.Amount,
+		GSTRate:       app.GSTRate,
+		Description:   app.Description,
+		FormData:      app.FormData,
+		AssignedCA:    app.AssignedCA,
+		Notes:         app.Notes,
+		CreatedAt:     app.CreatedAt,
+		UpdatedAt:     app.UpdatedAt,
+		User:          applicationUserResponse(app.User),
+
+		NextStatuses:        models.ApplicationStatusTransitions.Next(app.Status),
+		NextPaymentStatuses: models.PaymentStatusTransitions.Next(app.PaymentStatus),
+	}
+
+	if app.AssignedCAUser != nil {
+		assignedCA := applicationUserResponse(*app.AssignedCAUser)
+		response.AssignedCAUser = &assignedCA
+	}
+
+	for _, doc := range app.Documents {
+		response.Documents = append(response.Documents, documentResponse(doc))
+	}
+
+	for _, event := range app.Events {
+		response.Events = append(response.Events, applicationEventResponse(event))
+	}
+
+	if app.Service != nil {
+		response.DocumentStatus, response.DocumentChecklist, response.MissingDocuments = documentChecklist(app)
+	}
+
+	return response
+}
+
 // CreateApplication handles application creation by users
 func CreateApplication(c *gin.Context) {
 	userInterface, _ := c.Get("user")
 	currentUser := userInterface.(*models.User)
 
+	// Optionally require verified contact details before accepting applications
+	if config.GetVerificationConfig()["require_for_applications"] == "true" && !currentUser.IsVerified() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email and phone before creating an application"})
+		return
+	}
+
 	var req models.ApplicationCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Create application
+	service, err := findActiveService(req.ServiceType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown or inactive service"})
+		return
+	}
+
+	if service.FormSchema != nil {
+		if err := service.FormSchema.Validate(req.FormData); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data", "fields": err.(*models.FormValidationError).Fields})
+			return
+		}
+	} else if len(req.FormData) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This service doesn't take form data"})
+		return
+	}
+
+	// Create application, priced from the catalog
 	application := models.Application{
 		UserID:        currentUser.ID,
-		ServiceType:   req.ServiceType,
+		ServiceID:     &service.ID,
+		ServiceType:   service.Name,
 		Description:   req.Description,
-		Amount:        req.Amount,
-		Status:        "pending",
+		FormData:      req.FormData,
+		Amount:        service.TotalPrice(),
+		GSTRate:       service.GSTRate,
+		Status:        models.ApplicationStatusPending,
 		Progress:      "0%",
-		PaymentStatus: "pending",
+		PaymentStatus: models.PaymentStatusPending,
 	}
 
-	if err := database.DB.Create(&application).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&application).Error; err != nil {
+			return err
+		}
+		// Start the timeline with the initial status
+		return recordApplicationChanges(tx, &models.Application{ID: application.ID}, map[string]interface{}{"status": application.Status}, &currentUser.ID)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create application"})
 		return
 	}
@@ -56,7 +154,7 @@ func GetUserApplications(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset := (page - 1) * limit
 
-	query := database.DB.Where("user_id = ?", currentUser.ID).Preload("AssignedCAUser").Preload("Documents")
+	query := preloadApplicationDocuments(database.DB.Where("user_id = ?", currentUser.ID).Preload("AssignedCAUser"))
 
 	// Filter by status if provided
 	if status != "" {
@@ -76,49 +174,7 @@ func GetUserApplications(c *gin.Context) {
 	// Convert to response format
 	var responses []models.ApplicationResponse
 	for _, app := range applications {
-		response := models.ApplicationResponse{
-			ID:            app.ID,
-			UserID:        app.UserID,
-			ServiceType:   app.ServiceType,
-			Status:        app.Status,
-			Progress:      app.Progress,
-			PaymentStatus: app.PaymentStatus,
-			Amount:        app.Amount,
-			Description:   app.Description,
-			AssignedCA:    app.AssignedCA,
-			Notes:         app.Notes,
-			CreatedAt:     app.CreatedAt,
-			UpdatedAt:     app.UpdatedAt,
-		}
-
-		if app.AssignedCAUser != nil {
-			response.AssignedCAUser = &models.UserResponse{
-				ID:        app.AssignedCAUser.ID,
-				Email:     app.AssignedCAUser.Email,
-				Phone:     app.AssignedCAUser.Phone,
-				Name:      app.AssignedCAUser.Name,
-				Role:      app.AssignedCAUser.Role,
-				IsActive:  app.AssignedCAUser.IsActive,
-				CreatedAt: app.AssignedCAUser.CreatedAt,
-			}
-		}
-
-		// Add documents
-		for _, doc := range app.Documents {
-			response.Documents = append(response.Documents, models.DocumentResponse{
-				ID:            doc.ID,
-				ApplicationID: doc.ApplicationID,
-				FileName:      doc.FileName,
-				FilePath:      doc.FilePath,
-				FileSize:      doc.FileSize,
-				FileType:      doc.FileType,
-				Description:   doc.Description,
-				UploadedAt:    doc.UploadedAt,
-				CreatedAt:     doc.CreatedAt,
-			})
-		}
-
-		responses = append(responses, response)
+		responses = append(responses, applicationResponse(app))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -138,10 +194,10 @@ func GetApplication(c *gin.Context) {
 	currentUser := userInterface.(*models.User)
 
 	var application models.Application
-	query := database.DB.Preload("AssignedCAUser").Preload("Documents").Preload("User")
+	query := preloadApplicationEvents(preloadApplicationDocuments(database.DB.Preload("AssignedCAUser").Preload("User")))
 
 	// If user is not admin, only allow access to their own applications
-	if currentUser.Role != "admin" {
+	if !middleware.HasPermission(currentUser, models.PermissionApplicationsRead) {
 		query = query.Where("user_id = ?", currentUser.ID)
 	}
 
@@ -150,58 +206,7 @@ func GetApplication(c *gin.Context) {
 		return
 	}
 
-	response := models.ApplicationResponse{
-		ID:            application.ID,
-		UserID:        application.UserID,
-		ServiceType:   application.ServiceType,
-		Status:        application.Status,
-		Progress:      application.Progress,
-		PaymentStatus: application.PaymentStatus,
-		Amount:        application.Amount,
-		Description:   application.Description,
-		AssignedCA:    application.AssignedCA,
-		Notes:         application.Notes,
-		CreatedAt:     application.CreatedAt,
-		UpdatedAt:     application.UpdatedAt,
-	}
-
-	// Add user info
-	response.User = models.UserResponse{
-		ID:        application.User.ID,
-		Email:     application.User.Email,
-		Phone:     application.User.Phone,
-		Name:      application.User.Name,
-		Role:      application.User.Role,
-		IsActive:  application.User.IsActive,
-		CreatedAt: application.User.CreatedAt,
-	}
-
-	if application.AssignedCAUser != nil {
-		response.AssignedCAUser = &models.UserResponse{
-			ID:        application.AssignedCAUser.ID,
-			Email:     application.AssignedCAUser.Email,
-			Phone:     application.AssignedCAUser.Phone,
-			Name:      application.AssignedCAUser.Name,
-			Role:      application.AssignedCAUser.Role,
-			IsActive:  application.AssignedCAUser.IsActive,
-			CreatedAt: application.AssignedCAUser.CreatedAt,
-		}
-	}
-
-	// Add documents
-	for _, doc := range application.Documents {
-		response.Documents = append(response.Documents, models.DocumentResponse{
-			ID:            doc.ID,
-			ApplicationID: doc.ApplicationID,
-			FileName:      doc.FileName,
-			FilePath:      doc.FilePath,
-			FileSize:      doc.FileSize,
-			FileType:      doc.FileType,
-			Description:   doc.Description,
-			UploadedAt:    doc.UploadedAt,
-			CreatedAt:     doc.CreatedAt,
-		})
-	}
+	response := applicationResponse(application)
 
 	c.JSON(http.StatusOK, gin.H{"application": response})
 }
@@ -217,7 +222,7 @@ func GetAllApplications(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	offset := (page - 1) * limit
 
-	query := database.DB.Preload("User").Preload("AssignedCAUser").Preload("Documents")
+	query := preloadApplicationDocuments(database.DB.Preload("User").Preload("AssignedCAUser"))
 
 	// Apply filters
 	if status != "" {
@@ -226,6 +231,11 @@ func GetAllApplications(c *gin.Context) {
 	if serviceType != "" {
 		query = query.Where("service_type = ?", serviceType)
 	}
+	query, err := applyFormFilters(query, c.Request.URL.Query(), serviceType)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Get total count
 	var total int64
@@ -240,60 +250,7 @@ func GetAllApplications(c *gin.Context) {
 	// Convert to response format
 	var responses []models.ApplicationResponse
 	for _, app := range applications {
-		response := models.ApplicationResponse{
-			ID:            app.ID,
-			UserID:        app.UserID,
-			ServiceType:   app.ServiceType,
-			Status:        app.Status,
-			Progress:      app.Progress,
-			PaymentStatus: app.PaymentStatus,
-			Amount:        app.Amount,
-			Description:   app.Description,
-			AssignedCA:    app.AssignedCA,
-			Notes:         app.Notes,
-			CreatedAt:     app.CreatedAt,
-			UpdatedAt:     app.UpdatedAt,
-		}
-
-		// Add user info
-		response.User = models.UserResponse{
-			ID:        app.User.ID,
-			Email:     app.User.Email,
-			Phone:     app.User.Phone,
-			Name:      app.User.Name,
-			Role:      app.User.Role,
-			IsActive:  app.User.IsActive,
-			CreatedAt: app.User.CreatedAt,
-		}
-
-		if app.AssignedCAUser != nil {
-			response.AssignedCAUser = &models.UserResponse{
-				ID:        app.AssignedCAUser.ID,
-				Email:     app.AssignedCAUser.Email,
-				Phone:     app.AssignedCAUser.Phone,
-				Name:      app.AssignedCAUser.Name,
-				Role:      app.AssignedCAUser.Role,
-				IsActive:  app.AssignedCAUser.IsActive,
-				CreatedAt: app.AssignedCAUser.CreatedAt,
-			}
-		}
-
-		// Add documents
-		for _, doc := range app.Documents {
-			response.Documents = append(response.Documents, models.DocumentResponse{
-				ID:            doc.ID,
-				ApplicationID: doc.ApplicationID,
-				FileName:      doc.FileName,
-				FilePath:      doc.FilePath,
-				FileSize:      doc.FileSize,
-				FileType:      doc.FileType,
-				Description:   doc.Description,
-				UploadedAt:    doc.UploadedAt,
-				CreatedAt:     doc.CreatedAt,
-			})
-		}
-
-		responses = append(responses, response)
+		responses = append(responses, applicationResponse(app))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -309,6 +266,8 @@ func GetAllApplications(c *gin.Context) {
 // UpdateApplication updates an application (admin only)
 func UpdateApplication(c *gin.Context) {
 	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
 	
 	var req models.ApplicationUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -325,17 +284,54 @@ func UpdateApplication(c *gin.Context) {
 
 	// Update application
 	updates := make(map[string]interface{})
+	// Status changes only apply if the row still holds the status they were validated against
+	guard := map[string]interface{}{}
 	if req.Status != "" {
+		if err := models.ApplicationStatusTransitions.Validate(application.Status, req.Status); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":         fmt.Sprintf("Invalid status change: %v", err),
+				"next_statuses": models.ApplicationStatusTransitions.Next(application.Status),
+			})
+			return
+		}
 		updates["status"] = req.Status
+		guard["status"] = application.Status
 	}
 	if req.Progress != "" {
 		updates["progress"] = req.Progress
 	}
 	if req.PaymentStatus != "" {
+		// Refunds go through the refund flow so the gateway and payments agree
+		if req.PaymentStatus == models.PaymentStatusRefunded && application.PaymentStatus != models.PaymentStatusRefunded {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payment_status becomes refunded when the payment's refunds are processed; request a refund instead"})
+			return
+		}
+		if err := models.PaymentStatusTransitions.Validate(application.PaymentStatus, req.PaymentStatus); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":                 fmt.Sprintf("Invalid payment_status change: %v", err),
+				"next_payment_statuses": models.PaymentStatusTransitions.Next(application.PaymentStatus),
+			})
+			return
+		}
 		updates["payment_status"] = req.PaymentStatus
+		guard["payment_status"] = application.PaymentStatus
 	}
 	if req.AssignedCA != nil {
-		updates["assigned_ca"] = req.AssignedCA
+		if *req.AssignedCA == 0 {
+			// Zero unassigns the application
+			updates["assigned_ca"] = nil
+		} else {
+			var ca models.User
+			if err := database.DB.First(&ca, *req.AssignedCA).Error; err != nil || !ca.IsActive {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Assigned CA not found"})
+				return
+			}
+			if !middleware.HasPermission(&ca, models.PermissionApplicationsAssigned) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Assigned user's role can't work on assigned applications"})
+				return
+			}
+			updates["assigned_ca"] = req.AssignedCA
+		}
 	}
 	if req.Notes != "" {
 		updates["notes"] = req.Notes
@@ -344,66 +340,37 @@ func UpdateApplication(c *gin.Context) {
 		updates["amount"] = req.Amount
 	}
 
-	if err := database.DB.Model(&application).Updates(updates).Error; err != nil {
+	// Updates writes the new values into application, so keep the old ones for the timeline
+	before := application
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&application).Where(guard).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(updates) > 0 && result.RowsAffected == 0 {
+			return errApplicationConflict
+		}
+		return recordApplicationChanges(tx, &before, updates, &currentUser.ID)
+	})
+	if errors.Is(err, errApplicationConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Application was changed by someone else, reload and try again"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application"})
 		return
 	}
+	middleware.SetAuditChanges(c, applicationFieldValues(&before), updates)
 
-	// Get updated application with relationships
-	database.DB.Preload("User").Preload("AssignedCAUser").Preload("Documents").First(&application, id)
-
-	response := models.ApplicationResponse{
-		ID:            application.ID,
-		UserID:        application.UserID,
-		ServiceType:   application.ServiceType,
-		Status:        application.Status,
-		Progress:      application.Progress,
-		PaymentStatus: application.PaymentStatus,
-		Amount:        application.Amount,
-		Description:   application.Description,
-		AssignedCA:    application.AssignedCA,
-		Notes:         application.Notes,
-		CreatedAt:     application.CreatedAt,
-		UpdatedAt:     application.UpdatedAt,
-	}
-
-	// Add user info
-	response.User = models.UserResponse{
-		ID:        application.User.ID,
-		Email:     application.User.Email,
-		Phone:     application.User.Phone,
-		Name:      application.User.Name,
-		Role:      application.User.Role,
-		IsActive:  application.User.IsActive,
-		CreatedAt: application.User.CreatedAt,
-	}
-
-	if application.AssignedCAUser != nil {
-		response.AssignedCAUser = &models.UserResponse{
-			ID:        application.AssignedCAUser.ID,
-			Email:     application.AssignedCAUser.Email,
-			Phone:     application.AssignedCAUser.Phone,
-			Name:      application.AssignedCAUser.Name,
-			Role:      application.AssignedCAUser.Role,
-			IsActive:  application.AssignedCAUser.IsActive,
-			CreatedAt: application.AssignedCAUser.CreatedAt,
-		}
+	// Payments recorded by hand are invoiced like gateway payments
+	if updates["payment_status"] == models.PaymentStatusPaid {
+		issuePaidInvoice(c.Request.Context(), application.ID)
 	}
 
-	// Add documents
-	for _, doc := range application.Documents {
-		response.Documents = append(response.Documents, models.DocumentResponse{
-			ID:            doc.ID,
-			ApplicationID: doc.ApplicationID,
-			FileName:      doc.FileName,
-			FilePath:      doc.FilePath,
-			FileSize:      doc.FileSize,
-			FileType:      doc.FileType,
-			Description:   doc.Description,
-			UploadedAt:    doc.UploadedAt,
-			CreatedAt:     doc.CreatedAt,
-		})
-	}
+	// Get updated application with relationships
+	preloadApplicationEvents(preloadApplicationDocuments(database.DB.Preload("User").Preload("AssignedCAUser"))).First(&application, id)
+
+	response := applicationResponse(application)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Application updated successfully",
@@ -427,7 +394,7 @@ func GetApplicationStats(c *gin.Context) {
 	query := database.DB.Model(&models.Application{})
 	
 	// If user is not admin, only show their applications
-	if currentUser.Role != "admin" {
+	if !middleware.HasPermission(currentUser, models.PermissionApplicationsRead) {
 		query = query.Where("user_id = ?", currentUser.ID)
 	}
 
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"bharat-seva-space/models"
+
+	"gorm.io/gorm"
+)
+
+// trackedApplicationFields are the application columns whose changes are recorded as events
+var trackedApplicationFields = []string{"status", "progress", "payment_status", "amount", "assigned_ca"}
+
+// applicationFieldValues returns the current values of the tracked fields, plus notes for the audit log
+func applicationFieldValues(application *models.Application) map[string]interface{} {
+	return map[string]interface{}{
+		"status":         application.Status,
+		"progress":       application.Progress,
+		"payment_status": application.PaymentStatus,
+		"amount":         application.Amount,
+		"assigned_ca":    application.AssignedCA,
+		"notes":          application.Notes,
+	}
+}
+
+// formatEventValue renders a tracked field value as stored in the timeline
+func formatEventValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case *uint:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// recordApplicationChanges stores an event for each tracked field that the updates change
+func recordApplicationChanges(tx *gorm.DB, before *models.Application, updates map[string]interface{}, actorID *uint) error {
+	oldValues := applicationFieldValues(before)
+	for _, field := range trackedApplicationFields {
+		value, ok := updates[field]
+		if !ok {
+			continue
+		}
+		oldValue, newValue := formatEventValue(oldValues[field]), formatEventValue(value)
+		if oldValue == newValue {
+			continue
+		}
+		event := models.ApplicationEvent{
+			ApplicationID: before.ID,
+			ActorID:       actorID,
+			Field:         field,
+			OldValue:      oldValue,
+			NewValue:      newValue,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preloadApplicationEvents loads an application's timeline, oldest first, with the actors
+func preloadApplicationEvents(query *gorm.DB) *gorm.DB {
+	return query.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Preload("Events.Actor")
+}
+
+// applicationEventResponse converts an event to its API response
+func applicationEventResponse(event models.ApplicationEvent) models.ApplicationEventResponse {
+	response := models.ApplicationEventResponse{
+		ID:        event.ID,
+		Field:     event.Field,
+		OldValue:  event.OldValue,
+		NewValue:  event.NewValue,
+		ActorID:   event.ActorID,
+		CreatedAt: event.CreatedAt,
+	}
+	if event.Actor != nil {
+		response.ActorName = event.Actor.Name
+	}
+	return response
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"gorm.io/gorm"
+)
+
+// formFilterPrefix marks query parameters that filter on application form data, e.g. form.pan=ABCPS1234K
+const formFilterPrefix = "form."
+
+// filterableFormFields collects the fields marked x-filterable in service form schemas,
+// limited to one service when serviceType is set. The first service defining a field wins.
+func filterableFormFields(serviceType string) (map[string]*models.FormField, error) {
+	query := database.DB.Where("form_schema IS NOT NULL").Order("id")
+	if serviceType != "" {
+		query = query.Where("name = ?", serviceType)
+	}
+
+	var services []models.Service
+	if err := query.Find(&services).Error; err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]*models.FormField)
+	for _, service := range services {
+		for name, field := range service.FormSchema.Properties {
+			if _, ok := fields[name]; !ok && field.Filterable {
+				fields[name] = field
+			}
+		}
+	}
+	return fields, nil
+}
+
+// parseFormFilterValue converts a query string value to the field's JSON type
+func parseFormFilterValue(field *models.FormField, raw string) (interface{}, error) {
+	switch field.Type {
+	case models.FormFieldNumber, models.FormFieldInteger:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || (field.Type == models.FormFieldInteger && n != math.Trunc(n)) {
+			return nil, fmt.Errorf("must be a %s", field.Type)
+		}
+		return n, nil
+	case models.FormFieldBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// applyFormFilters adds a containment condition for every form.<field> query parameter.
+// Only filterable fields are accepted so listings can't be used to probe arbitrary form data.
+func applyFormFilters(query *gorm.DB, params url.Values, serviceType string) (*gorm.DB, error) {
+	filters := make(map[string]interface{})
+	var names []string
+	for key := range params {
+		if strings.HasPrefix(key, formFilterPrefix) {
+			names = append(names, strings.TrimPrefix(key, formFilterPrefix))
+		}
+	}
+	if len(names) == 0 {
+		return query, nil
+	}
+	sort.Strings(names)
+
+	fields, err := filterableFormFields(serviceType)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("form field %q can't be filtered on", name)
+		}
+		value, err := parseFormFilterValue(field, params.Get(formFilterPrefix+name))
+		if err != nil {
+			return nil, fmt.Errorf("form field %q %v", name, err)
+		}
+		filters[name] = value
+	}
+
+	// A single jsonb containment check can use the GIN index on form_data
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+	return query.Where("form_data @> ?", string(encoded)), nil
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// parseAuditTime accepts either a date (YYYY-MM-DD) or an RFC 3339 timestamp
+func parseAuditTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// auditLogQuery builds the audit log query from the request's filters
+func auditLogQuery(c *gin.Context) (*gorm.DB, error) {
+	query := database.DB.Model(&models.AuditLog{})
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if entityType := c.Query("entity_type"); entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if method := c.Query("method"); method != "" {
+		query = query.Where("method = ?", method)
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := parseAuditTime(since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since, expected YYYY-MM-DD or RFC 3339")
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := parseAuditTime(until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid until, expected YYYY-MM-DD or RFC 3339")
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	return query, nil
+}
+
+// GetAuditLogs returns the admin audit log, newest first (admin only)
+func GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	query, err := auditLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	var logs []models.AuditLog
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audit_logs": logs,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// ExportAuditLogs downloads the filtered audit log as CSV or JSON, oldest first (admin only)
+func ExportAuditLogs(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be csv or json"})
+		return
+	}
+
+	query, err := auditLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at, id").Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	fileName := fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if format == "json" {
+		c.JSON(http.StatusOK, logs)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"id", "created_at", "actor_id", "actor_email", "method", "route", "path", "entity_type", "entity_id", "status_code", "ip_address", "changes", "request"})
+	for _, entry := range logs {
+		changes := ""
+		if len(entry.Changes) > 0 {
+			encoded, _ := json.Marshal(entry.Changes)
+			changes = string(encoded)
+		}
+		writer.Write([]string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.CreatedAt.Format(time.RFC3339),
+			strconv.FormatUint(uint64(entry.ActorID), 10),
+			entry.ActorEmail,
+			entry.Method,
+			entry.Route,
+			entry.Path,
+			entry.EntityType,
+			entry.EntityID,
+			strconv.Itoa(entry.StatusCode),
+			entry.IPAddress,
+			changes,
+			entry.Request,
+		})
+	}
+	writer.Flush()
+}
@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"bharat-seva-space/config"
 	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
 	"bharat-seva-space/models"
 	"bharat-seva-space/utils"
 
@@ -59,8 +64,16 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user)
+	// Send email and phone verification codes; the user can request new ones if delivery fails
+	for _, channel := range []string{"email", "phone"} {
+		purpose, destination := verificationTarget(&user, channel)
+		if err := issueOTP(c.Request.Context(), &user.ID, purpose, channel, destination); err != nil {
+			log.Printf("Failed to send %s verification code to user %d: %v", channel, user.ID, err)
+		}
+	}
+
+	// Start a session and generate tokens
+	_, token, refreshToken, err := createSession(database.DB, c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -71,20 +84,28 @@ func Register(c *gin.Context) {
 
 	// Return user response
 	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+		ID:                 user.ID,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		Name:               user.Name,
+		Role:               user.Role,
+		IsActive:           user.IsActive,
+		MustChangePassword: user.MustChangePassword,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		PhoneVerified:      user.PhoneVerifiedAt != nil,
+		TwoFactorEnabled:   user.TOTPEnabled,
+		State:              user.State,
+		GSTIN:              user.GSTIN,
+		CreatedAt:          user.CreatedAt,
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "User registered successfully",
-		"user":    userResponse,
-		"token":   token,
+		"success":               true,
+		"message":               "User registered successfully",
+		"user":                  userResponse,
+		"token":                 token,
+		"refresh_token":         refreshToken,
+		"verification_required": true,
 	})
 }
 
@@ -99,9 +120,18 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Reject clients with too many recent failures before doing any password hashing
+	settings := getLoginProtectionSettings()
+	if wait := ipBlockedFor(c, settings); wait > 0 {
+		recordLoginAttempt(c, nil, req.Email, false, loginReasonIPBlocked)
+		respondLocked(c, "Too many failed login attempts from this address", wait)
+		return
+	}
+
 	// Find user by email
 	var user models.User
 	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		recordLoginAttempt(c, nil, req.Email, false, loginReasonInvalidCredentials)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"message": "Invalid credentials",
@@ -109,8 +139,16 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Check if the account is temporarily locked
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		recordLoginAttempt(c, &user.ID, req.Email, false, loginReasonAccountLocked)
+		respondLocked(c, "Account temporarily locked due to failed login attempts", time.Until(*user.LockedUntil))
+		return
+	}
+
 	// Check if user is active
 	if !user.IsActive {
+		recordLoginAttempt(c, &user.ID, req.Email, false, loginReasonDeactivated)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"message": "Account is deactivated",
@@ -120,6 +158,11 @@ func Login(c *gin.Context) {
 
 	// Check password
 	if !utils.CheckPassword(req.Password, user.Password) {
+		recordLoginAttempt(c, &user.ID, req.Email, false, loginReasonInvalidCredentials)
+		if lockout := registerFailedLogin(&user, settings); lockout > 0 {
+			respondLocked(c, "Account temporarily locked due to failed login attempts", lockout)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
 			"message": "Invalid credentials",
@@ -127,32 +170,117 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to generate token",
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		resetFailedLogins(user.ID)
+	}
+	recordLoginAttempt(c, &user.ID, req.Email, true, "")
+
+	completeLogin(c, user)
+}
+
+// completeLogin responds to a successful first-factor authentication. Users with
+// two-factor authentication enabled get a pending token to exchange with a TOTP code.
+func completeLogin(c *gin.Context, user models.User) {
+	if user.TOTPEnabled {
+		token, err := utils.GenerateScopedToken(user, utils.ScopeTwoFactorPending)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to generate token",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":             true,
+			"message":             "Two-factor authentication required",
+			"token":               token,
+			"two_factor_required": true,
 		})
 		return
 	}
 
+	finishLogin(c, user)
+}
+
+// finishLogin responds to a fully authenticated login with a session and tokens,
+// or with a restricted token when the user must change their password or enroll in 2FA
+func finishLogin(c *gin.Context, user models.User) {
 	// Return user response
 	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+		ID:                 user.ID,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		Name:               user.Name,
+		Role:               user.Role,
+		IsActive:           user.IsActive,
+		MustChangePassword: user.MustChangePassword,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		PhoneVerified:      user.PhoneVerifiedAt != nil,
+		TwoFactorEnabled:   user.TOTPEnabled,
+		State:              user.State,
+		GSTIN:              user.GSTIN,
+		CreatedAt:          user.CreatedAt,
+	}
+
+	// A forced password change only gets a restricted token for the change-password call
+	if user.MustChangePassword {
+		token, err := utils.GenerateScopedToken(user, utils.ScopePasswordChange)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to generate token",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":                  true,
+			"message":                  "Password change required",
+			"user":                     userResponse,
+			"token":                    token,
+			"password_change_required": true,
+		})
+		return
+	}
+
+	// Staff without 2FA only get a restricted token for enrollment when 2FA is mandatory
+	if !user.TOTPEnabled && middleware.IsStaff(&user) && config.GetAuthConfig()["require_admin_2fa"] == "true" {
+		token, err := utils.GenerateScopedToken(user, utils.ScopeTwoFactorSetup)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to generate token",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":                   true,
+			"message":                   "Two-factor authentication setup required",
+			"user":                      userResponse,
+			"token":                     token,
+			"two_factor_setup_required": true,
+		})
+		return
+	}
+
+	// Start a session and generate tokens
+	_, token, refreshToken, err := createSession(database.DB, c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to generate token",
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Login successful",
-		"user":    userResponse,
-		"token":   token,
+		"success":       true,
+		"message":       "Login successful",
+		"user":          userResponse,
+		"token":         token,
+		"refresh_token": refreshToken,
 	})
 }
 
@@ -162,18 +290,25 @@ func GetProfile(c *gin.Context) {
 	user := userInterface.(*models.User)
 
 	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+		ID:                 user.ID,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		Name:               user.Name,
+		Role:               user.Role,
+		IsActive:           user.IsActive,
+		MustChangePassword: user.MustChangePassword,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		PhoneVerified:      user.PhoneVerifiedAt != nil,
+		TwoFactorEnabled:   user.TOTPEnabled,
+		State:              user.State,
+		GSTIN:              user.GSTIN,
+		CreatedAt:          user.CreatedAt,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"user":    userResponse,
+		"success":     true,
+		"user":        userResponse,
+		"permissions": middleware.UserPermissions(user),
 	})
 }
 
@@ -198,6 +333,37 @@ func UpdateProfile(c *gin.Context) {
 	}
 	if req.Phone != "" {
 		updates["phone"] = req.Phone
+		// A new phone number has to be verified again
+		if req.Phone != currentUser.Phone {
+			updates["phone_verified_at"] = nil
+		}
+	}
+	if req.State != nil {
+		if *req.State != "" {
+			if _, ok := models.GSTStates[*req.State]; !ok {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"message": "Unknown state code",
+				})
+				return
+			}
+		}
+		updates["state"] = *req.State
+	}
+	if req.GSTIN != nil {
+		gstin := strings.ToUpper(strings.TrimSpace(*req.GSTIN))
+		if gstin != "" {
+			if !models.GSTINPattern.MatchString(gstin) || models.GSTStates[gstin[:2]] == "" {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"success": false,
+					"message": "Invalid GSTIN",
+				})
+				return
+			}
+			// A registered customer is located in the state of their GSTIN
+			updates["state"] = gstin[:2]
+		}
+		updates["gstin"] = gstin
 	}
 
 	if err := database.DB.Model(&models.User{}).Where("id = ?", currentUser.ID).Updates(updates).Error; err != nil {
@@ -207,19 +373,26 @@ func UpdateProfile(c *gin.Context) {
 		})
 		return
 	}
+	middleware.InvalidateUserCache(currentUser.ID)
 
 	// Get updated user
 	var updatedUser models.User
 	database.DB.First(&updatedUser, currentUser.ID)
 
 	userResponse := models.UserResponse{
-		ID:        updatedUser.ID,
-		Email:     updatedUser.Email,
-		Phone:     updatedUser.Phone,
-		Name:      updatedUser.Name,
-		Role:      updatedUser.Role,
-		IsActive:  updatedUser.IsActive,
-		CreatedAt: updatedUser.CreatedAt,
+		ID:                 updatedUser.ID,
+		Email:              updatedUser.Email,
+		Phone:              updatedUser.Phone,
+		Name:               updatedUser.Name,
+		Role:               updatedUser.Role,
+		IsActive:           updatedUser.IsActive,
+		MustChangePassword: updatedUser.MustChangePassword,
+		EmailVerified:      updatedUser.EmailVerifiedAt != nil,
+		PhoneVerified:      updatedUser.PhoneVerifiedAt != nil,
+		TwoFactorEnabled:   updatedUser.TOTPEnabled,
+		State:              updatedUser.State,
+		GSTIN:              updatedUser.GSTIN,
+		CreatedAt:          updatedUser.CreatedAt,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -227,4 +400,91 @@ func UpdateProfile(c *gin.Context) {
 		"message": "Profile updated successfully",
 		"user":    userResponse,
 	})
-} 
\ No newline at end of file
+}
+
+// ChangePassword changes the current user's password after verifying the current one
+func ChangePassword(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// The user in context may come from the cache; verify against the stored hash
+	var user models.User
+	if err := database.DB.First(&user, currentUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"message": "User not found",
+		})
+		return
+	}
+
+	if !utils.CheckPassword(req.CurrentPassword, user.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Current password is incorrect",
+		})
+		return
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "New password must be different from the current password",
+		})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to hash password",
+		})
+		return
+	}
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"password":             hashedPassword,
+		"must_change_password": false,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to change password",
+		})
+		return
+	}
+
+	// Sign out all other sessions and start a fresh one for this client
+	if err := revokeUserSessions(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to revoke sessions",
+		})
+		return
+	}
+	middleware.InvalidateUserCache(user.ID)
+
+	_, token, refreshToken, err := createSession(database.DB, c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to generate token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"message":       "Password changed successfully",
+		"token":         token,
+		"refresh_token": refreshToken,
+	})
+}
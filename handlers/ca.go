@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// findAssignedApplication loads an application assigned to the current user as CA
+func findAssignedApplication(currentUser *models.User, id string) (*models.Application, error) {
+	var application models.Application
+	if err := database.DB.Where("assigned_ca = ?", currentUser.ID).First(&application, id).Error; err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+// GetCAApplications returns the applications assigned to the current CA
+func GetCAApplications(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var applications []models.Application
+
+	// Get query parameters
+	status := c.Query("status")
+	serviceType := c.Query("service_type")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset := (page - 1) * limit
+
+	query := preloadApplicationDocuments(database.DB.Where("assigned_ca = ?", currentUser.ID).Preload("User"))
+
+	// Apply filters
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if serviceType != "" {
+		query = query.Where("service_type = ?", serviceType)
+	}
+
+	// Get total count
+	var total int64
+	query.Model(&models.Application{}).Count(&total)
+
+	// Get paginated results
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&applications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		return
+	}
+
+	var responses []models.ApplicationResponse
+	for _, app := range applications {
+		responses = append(responses, applicationResponse(app))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"applications": responses,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetCAApplication returns an application assigned to the current CA
+func GetCAApplication(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAssignedApplication(currentUser, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	preloadApplicationEvents(preloadApplicationDocuments(database.DB.Preload("User").Preload("AssignedCAUser"))).First(application, application.ID)
+
+	c.JSON(http.StatusOK, gin.H{"application": applicationResponse(*application)})
+}
+
+// UpdateCAApplication lets the assigned CA update an application's progress and notes
+func UpdateCAApplication(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.CAApplicationUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	application, err := findAssignedApplication(currentUser, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Progress != "" {
+		updates["progress"] = req.Progress
+	}
+	if req.Notes != "" {
+		updates["notes"] = req.Notes
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+	// Work on a closed application is over; staff reopen cancelled ones if needed
+	if application.Status == models.ApplicationStatusCompleted || application.Status == models.ApplicationStatusCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Application is %s and can't be updated", application.Status)})
+		return
+	}
+
+	// Updates writes the new values into application, so keep the old ones for the timeline
+	before := *application
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Guarded on the status so the application can't be closed in the meantime
+		result := tx.Model(application).Where("status = ?", before.Status).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errApplicationConflict
+		}
+		return recordApplicationChanges(tx, &before, updates, &currentUser.ID)
+	})
+	if errors.Is(err, errApplicationConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Application was changed by someone else, reload and try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update application"})
+		return
+	}
+
+	// Get updated application with relationships
+	preloadApplicationEvents(preloadApplicationDocuments(database.DB.Preload("User").Preload("AssignedCAUser"))).First(application, application.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Application updated successfully",
+		"application": applicationResponse(*application),
+	})
+}
+
+// GetCAApplicationDocuments returns the documents of an application assigned to the current CA
+func GetCAApplicationDocuments(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAssignedApplication(currentUser, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	var documents []models.Document
+	if err := database.DB.Where("application_id = ?", application.ID).Order("uploaded_at DESC").Find(&documents).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		return
+	}
+
+	var responses []models.DocumentResponse
+	for _, doc := range documents {
+		responses = append(responses, documentResponse(doc))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": responses})
+}
+
+// UploadCADeliverable uploads a deliverable document to an application assigned to the current CA
+func UploadCADeliverable(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAssignedApplication(currentUser, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	document, ok := saveUploadedDocument(c, application, models.DocumentKindDeliverable, currentUser.ID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Deliverable uploaded successfully",
+		"document": documentResponse(*document),
+	})
+}
+
+// DownloadCADocument streams a document of an application assigned to the current CA
+func DownloadCADocument(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAssignedApplication(currentUser, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	document, err := findApplicationDocument(application.ID, c.Param("doc_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	streamDocument(c, document)
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/storage"
+	"bharat-seva-space/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaxFileSize is used when MAX_FILE_SIZE is missing or invalid (10 MB)
+const defaultMaxFileSize int64 = 10 << 20
+
+// findAccessibleApplication loads an application the current user may access.
+// Staff whose role grants the permission can access any application, users only their own.
+func findAccessibleApplication(currentUser *models.User, id string, permission string) (*models.Application, error) {
+	var application models.Application
+	query := database.DB
+
+	// Without the permission, only allow access to their own applications
+	if !middleware.HasPermission(currentUser, permission) {
+		query = query.Where("user_id = ?", currentUser.ID)
+	}
+
+	if err := query.First(&application, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &application, nil
+}
+
+// findApplicationDocument loads a document belonging to the given application
+func findApplicationDocument(applicationID uint, docID string) (*models.Document, error) {
+	var document models.Document
+	if err := database.DB.Where("application_id = ?", applicationID).First(&document, docID).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// documentResponse converts a document to its API response with a signed download URL
+func documentResponse(doc models.Document) models.DocumentResponse {
+	downloadURL, expiresAt := utils.SignedDownloadURL(doc.ID)
+	return models.DocumentResponse{
+		ID:                   doc.ID,
+		ApplicationID:        doc.ApplicationID,
+		FileName:             doc.FileName,
+		FileSize:             doc.FileSize,
+		FileType:             doc.FileType,
+		Description:          doc.Description,
+		Kind:                 doc.Kind,
+		UploadedBy:           doc.UploadedBy,
+		RequirementID:        doc.RequirementID,
+		DownloadURL:          downloadURL,
+		DownloadURLExpiresAt: expiresAt,
+		UploadedAt:           doc.UploadedAt,
+		CreatedAt:            doc.CreatedAt,
+	}
+}
+
+// sanitizeFileName strips any directory components and unsafe characters from an uploaded file name
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		name = "file"
+	}
+	return name
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// UploadDocument handles multipart document upload for an application
+func UploadDocument(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionApplicationsUpdate)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	document, ok := saveUploadedDocument(c, application, models.DocumentKindUpload, currentUser.ID)
+	if !ok {
+		return
+	}
+
+	response := documentResponse(*document)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Document uploaded successfully",
+		"document": response,
+	})
+}
+
+// saveUploadedDocument stores the multipart "file" field for an application and records
+// it as a document of the given kind. Uploads may name the service requirement they
+// satisfy in the "requirement" field. On failure it writes the error response and returns false.
+func saveUploadedDocument(c *gin.Context, application *models.Application, kind string, uploadedBy uint) (*models.Document, bool) {
+	maxFileSize := config.GetUploadConfig()["max_file_size"].(int64)
+	if maxFileSize <= 0 {
+		maxFileSize = defaultMaxFileSize
+	}
+
+	// Limit the request body so oversized uploads are rejected before being buffered.
+	// Allow some headroom for multipart boundaries and form fields.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileSize+(1<<20))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds maximum size of %d bytes", maxFileSize)})
+			return nil, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return nil, false
+	}
+
+	if fileHeader.Size > maxFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds maximum size of %d bytes", maxFileSize)})
+		return nil, false
+	}
+
+	var requirementID *uint
+	if kind == models.DocumentKindUpload {
+		if requirementID, err = findApplicationRequirement(application, c.PostForm("requirement")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown document requirement for this service"})
+			return nil, false
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return nil, false
+	}
+	defer file.Close()
+
+	// Sniff the content type from the file contents rather than trusting the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return nil, false
+	}
+	fileType := http.DetectContentType(head[:n])
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read uploaded file"})
+		return nil, false
+	}
+
+	// Store the file under a per-application prefix with a random component
+	prefix, err := randomHex(8)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return nil, false
+	}
+	fileName := sanitizeFileName(fileHeader.Filename)
+	key := fmt.Sprintf("%d/%s_%s", application.ID, prefix, fileName)
+
+	if err := storage.Store.Save(c.Request.Context(), key, file, fileHeader.Size, fileType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return nil, false
+	}
+
+	document := models.Document{
+		ApplicationID: application.ID,
+		FileName:      fileName,
+		FilePath:      key,
+		FileSize:      fileHeader.Size,
+		FileType:      fileType,
+		Description:   c.PostForm("description"),
+		Kind:          kind,
+		UploadedBy:    &uploadedBy,
+		RequirementID: requirementID,
+		UploadedAt:    time.Now(),
+	}
+
+	if err := database.DB.Create(&document).Error; err != nil {
+		storage.Store.Delete(c.Request.Context(), key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save document"})
+		return nil, false
+	}
+
+	return &document, true
+}
+
+// GetApplicationDocuments returns the documents attached to an application
+func GetApplicationDocuments(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionApplicationsRead)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	var documents []models.Document
+	if err := database.DB.Where("application_id = ?", application.ID).Order("uploaded_at DESC").Find(&documents).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch documents"})
+		return
+	}
+
+	var responses []models.DocumentResponse
+	for _, doc := range documents {
+		responses = append(responses, documentResponse(doc))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"documents": responses})
+}
+
+// DownloadDocument streams a document belonging to an application
+func DownloadDocument(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionApplicationsRead)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	document, err := findApplicationDocument(application.ID, c.Param("doc_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	streamDocument(c, document)
+}
+
+// DownloadSignedDocument streams a document using a signed, expiring download URL.
+// No authentication is required; access is granted by the signature.
+func DownloadSignedDocument(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	if !utils.VerifyDownloadSignature(uint(id), c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+
+	var document models.Document
+	if err := database.DB.First(&document, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	streamDocument(c, &document)
+}
+
+// streamDocument writes a stored document to the response as an attachment
+func streamDocument(c *gin.Context, document *models.Document) {
+	reader, err := storage.Store.Open(c.Request.Context(), document.FilePath)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document file not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read document"})
+		return
+	}
+	defer reader.Close()
+
+	contentType := document.FileType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	c.DataFromReader(http.StatusOK, document.FileSize, contentType, reader, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", document.FileName),
+	})
+}
+
+// DeleteDocument removes a document from an application
+func DeleteDocument(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionApplicationsUpdate)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	document, err := findApplicationDocument(application.ID, c.Param("doc_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+
+	// Deliverables belong to the CA's work and can only be removed by staff
+	if document.Kind == models.DocumentKindDeliverable && !middleware.HasPermission(currentUser, models.PermissionApplicationsUpdate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Deliverables can't be deleted"})
+		return
+	}
+	// Issued invoices are kept for tax records
+	if document.Kind == models.DocumentKindInvoice {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invoices can't be deleted"})
+		return
+	}
+
+	if err := database.DB.Delete(document).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document"})
+		return
+	}
+
+	if err := storage.Store.Delete(c.Request.Context(), document.FilePath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete document file"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted successfully"})
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errUnknownRequirement = errors.New("unknown document requirement for this service")
+
+// orderRequirements sorts preloaded requirements in checklist order
+func orderRequirements(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, id")
+}
+
+// preloadApplicationDocuments loads an application's documents together with its
+// service's requirements, which is what the document checklist needs
+func preloadApplicationDocuments(query *gorm.DB) *gorm.DB {
+	return query.Preload("Documents").Preload("Service.DocumentRequirements", orderRequirements)
+}
+
+// buildDocumentRequirements validates requirement definitions, deriving missing keys from names
+func buildDocumentRequirements(requests []models.DocumentRequirementRequest) ([]models.DocumentRequirement, error) {
+	requirements := make([]models.DocumentRequirement, 0, len(requests))
+	seen := make(map[string]bool)
+	for i, req := range requests {
+		key := req.Key
+		if key == "" {
+			key = slugify(req.Name)
+		}
+		if !serviceSlugPattern.MatchString(key) {
+			return nil, fmt.Errorf("requirement key %q must be lowercase letters and digits separated by hyphens", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate requirement key %q", key)
+		}
+		seen[key] = true
+
+		requirements = append(requirements, models.DocumentRequirement{
+			Key:         key,
+			Name:        req.Name,
+			Description: req.Description,
+			Optional:    req.Optional,
+			SortOrder:   i,
+		})
+	}
+	return requirements, nil
+}
+
+// replaceDocumentRequirements makes a service's requirements match the given list.
+// Requirements are matched by key so documents already tagged keep their tag;
+// documents tagged with a removed requirement become untagged.
+func replaceDocumentRequirements(tx *gorm.DB, serviceID uint, requirements []models.DocumentRequirement) error {
+	var existing []models.DocumentRequirement
+	if err := tx.Where("service_id = ?", serviceID).Find(&existing).Error; err != nil {
+		return err
+	}
+	byKey := make(map[string]models.DocumentRequirement, len(existing))
+	for _, requirement := range existing {
+		byKey[requirement.Key] = requirement
+	}
+
+	for _, requirement := range requirements {
+		requirement.ServiceID = serviceID
+		if current, ok := byKey[requirement.Key]; ok {
+			requirement.ID = current.ID
+			if err := tx.Model(&requirement).Select("name", "description", "optional", "sort_order").Updates(&requirement).Error; err != nil {
+				return err
+			}
+			delete(byKey, requirement.Key)
+			continue
+		}
+		if err := tx.Create(&requirement).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, removed := range byKey {
+		if err := tx.Model(&models.Document{}).Where("requirement_id = ?", removed.ID).Update("requirement_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&removed).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findApplicationRequirement resolves a requirement key for an application's service.
+// An empty key means the document isn't tagged.
+func findApplicationRequirement(application *models.Application, key string) (*uint, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if application.ServiceID == nil {
+		return nil, errUnknownRequirement
+	}
+
+	var requirement models.DocumentRequirement
+	if err := database.DB.Where("service_id = ? AND key = ?", *application.ServiceID, key).First(&requirement).Error; err != nil {
+		return nil, errUnknownRequirement
+	}
+	return &requirement.ID, nil
+}
+
+// documentChecklist matches an application's documents against its service's
+// requirements. Deliverables never count towards a requirement.
+func documentChecklist(app models.Application) (string, []models.DocumentChecklistItem, []string) {
+	status := models.DocumentStatusComplete
+	checklist := make([]models.DocumentChecklistItem, 0, len(app.Service.DocumentRequirements))
+	var missing []string
+
+	for _, requirement := range app.Service.DocumentRequirements {
+		item := models.DocumentChecklistItem{
+			Key:         requirement.Key,
+			Name:        requirement.Name,
+			Description: requirement.Description,
+			Optional:    requirement.Optional,
+			DocumentIDs: []uint{},
+		}
+		for _, doc := range app.Documents {
+			if doc.Kind != models.DocumentKindDeliverable && doc.RequirementID != nil && *doc.RequirementID == requirement.ID {
+				item.DocumentIDs = append(item.DocumentIDs, doc.ID)
+			}
+		}
+		item.Satisfied = len(item.DocumentIDs) > 0
+
+		if !item.Satisfied && !requirement.Optional {
+			status = models.DocumentStatusIncomplete
+			missing = append(missing, requirement.Name)
+		}
+		checklist = append(checklist, item)
+	}
+
+	return status, checklist, missing
+}
+
+// TagDocument sets or clears the requirement an uploaded document satisfies
+func TagDocument(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.DocumentTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionApplicationsUpdate)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	document, err := findApplicationDocument(application.ID, c.Param("doc_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		return
+	}
+	if document.Kind != models.DocumentKindUpload {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only uploaded documents can be tagged with a requirement"})
+		return
+	}
+
+	requirementID, err := findApplicationRequirement(application, req.Requirement)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown document requirement for this service"})
+		return
+	}
+
+	if err := database.DB.Model(document).Update("requirement_id", requirementID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update document"})
+		return
+	}
+
+	document.RequirementID = requirementID
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Document updated successfully",
+		"document": documentResponse(*document),
+	})
+}
@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/invoice"
+	"bharat-seva-space/models"
+	"bharat-seva-space/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errInvoiceNotPaid       = errors.New("application isn't paid")
+	errInvoicingUnavailable = errors.New("invoicing needs a valid INVOICE_SELLER_GSTIN")
+)
+
+// invoiceSeller returns the seller details printed on invoices, or an error when
+// the seller's GSTIN isn't configured
+func invoiceSeller() (map[string]string, error) {
+	seller := config.GetInvoiceConfig()
+	seller["seller_gstin"] = strings.ToUpper(strings.TrimSpace(seller["seller_gstin"]))
+	if !models.GSTINPattern.MatchString(seller["seller_gstin"]) {
+		return nil, errInvoicingUnavailable
+	}
+	if seller["prefix"] == "" {
+		seller["prefix"] = "INV"
+	}
+	if seller["seller_name"] == "" {
+		seller["seller_name"] = "Bharat Seva Space"
+	}
+	return seller, nil
+}
+
+// customerState returns the GST state code of a customer: the state of their
+// GSTIN when registered, otherwise the state on their profile
+func customerState(user *models.User) string {
+	if user.GSTIN != "" {
+		return user.GSTIN[:2]
+	}
+	return user.State
+}
+
+// issueInvoice issues the tax invoice for a paid application. It returns the
+// existing invoice when one was already issued. The number is taken from the
+// financial year's sequence in the same transaction that records the invoice,
+// so numbers have no gaps.
+func issueInvoice(ctx context.Context, applicationID uint) (*models.Invoice, error) {
+	var existing models.Invoice
+	err := database.DB.Where("application_id = ?", applicationID).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var application models.Application
+	if err := database.DB.Preload("User").Preload("Service").First(&application, applicationID).Error; err != nil {
+		return nil, err
+	}
+	if application.PaymentStatus != models.PaymentStatusPaid {
+		return nil, errInvoiceNotPaid
+	}
+	seller, err := invoiceSeller()
+	if err != nil {
+		return nil, err
+	}
+
+	// Services are supplied where the customer is; without a known state the
+	// place of supply is the seller's own state
+	sellerState := seller["seller_gstin"][:2]
+	placeOfSupply := customerState(&application.User)
+	if placeOfSupply == "" {
+		placeOfSupply = sellerState
+	}
+	supplyType := models.SupplyIntraState
+	if placeOfSupply != sellerState {
+		supplyType = models.SupplyInterState
+	}
+	gstRate := invoiceGSTRate(&application)
+	taxable, cgst, sgst, igst := invoice.SplitGST(application.Amount, gstRate, supplyType == models.SupplyInterState)
+
+	now := time.Now()
+	record := models.Invoice{
+		FinancialYear: invoice.FinancialYear(now),
+		ApplicationID: application.ID,
+		IssuedAt:      now,
+		SellerName:    seller["seller_name"],
+		SellerAddress: seller["seller_address"],
+		SellerGSTIN:   seller["seller_gstin"],
+		SellerState:   sellerState,
+		CustomerName:  application.User.Name,
+		CustomerEmail: application.User.Email,
+		CustomerGSTIN: application.User.GSTIN,
+		PlaceOfSupply: placeOfSupply,
+		SupplyType:    supplyType,
+		Description:   application.ServiceType,
+		SAC:           seller["sac"],
+		TaxableAmount: taxable,
+		GSTRate:       gstRate,
+		CGST:          cgst,
+		SGST:          sgst,
+		IGST:          igst,
+		Total:         application.Amount,
+	}
+
+	var key string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Locks the year's sequence row until the invoice is recorded
+		var sequence models.InvoiceSequence
+		if err := tx.Raw(`INSERT INTO invoice_sequences (financial_year, last_number) VALUES (?, 1)
+			ON CONFLICT (financial_year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+			RETURNING financial_year, last_number`, record.FinancialYear).Scan(&sequence).Error; err != nil {
+			return err
+		}
+		record.Sequence = sequence.LastNumber
+		record.Number = invoice.Number(seller["prefix"], record.FinancialYear, record.Sequence)
+
+		pdf := invoice.Render(&record)
+		prefix, err := randomHex(8)
+		if err != nil {
+			return err
+		}
+		fileName := "invoice-" + strings.ReplaceAll(record.Number, "/", "-") + ".pdf"
+		key = fmt.Sprintf("%d/%s_%s", application.ID, prefix, fileName)
+		if err := storage.Store.Save(ctx, key, bytes.NewReader(pdf), int64(len(pdf)), "application/pdf"); err != nil {
+			key = ""
+			return err
+		}
+
+		document := models.Document{
+			ApplicationID: application.ID,
+			FileName:      fileName,
+			FilePath:      key,
+			FileSize:      int64(len(pdf)),
+			FileType:      "application/pdf",
+			Description:   "Tax invoice " + record.Number,
+			Kind:          models.DocumentKindInvoice,
+			UploadedAt:    now,
+		}
+		if err := tx.Create(&document).Error; err != nil {
+			return err
+		}
+		record.DocumentID = document.ID
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		if key != "" {
+			storage.Store.Delete(ctx, key)
+		}
+		// A concurrent call issued the invoice first; its sequence number was
+		// taken after ours was rolled back, so there's no gap
+		if err := database.DB.Where("application_id = ?", applicationID).First(&existing).Error; err == nil {
+			return &existing, nil
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// invoiceGSTRate returns the GST rate included in an application's amount.
+// Applications created before prices came from the catalog have no rate
+// recorded, so they fall back to their service's rate or the default rate.
+func invoiceGSTRate(application *models.Application) float64 {
+	if application.GSTRate > 0 {
+		return application.GSTRate
+	}
+	if application.Service != nil {
+		return application.Service.GSTRate
+	}
+	return models.DefaultGSTRate
+}
+
+// issuePaidInvoice issues the invoice for an application that was just paid.
+// Failures are logged rather than undoing the payment; the invoice can be
+// issued later with POST /api/admin/invoices.
+func issuePaidInvoice(ctx context.Context, applicationID uint) {
+	if _, err := issueInvoice(ctx, applicationID); err != nil && !errors.Is(err, errInvoiceNotPaid) {
+		log.Printf("Failed to issue invoice for application %d: %v", applicationID, err)
+	}
+}
+
+// streamInvoice writes an invoice's PDF to the response
+func streamInvoice(c *gin.Context, inv *models.Invoice) {
+	var document models.Document
+	if err := database.DB.First(&document, inv.DocumentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice file not found"})
+		return
+	}
+	streamDocument(c, &document)
+}
+
+// DownloadApplicationInvoice downloads the tax invoice of a paid application
+func DownloadApplicationInvoice(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionPaymentsRead)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	var inv models.Invoice
+	if err := database.DB.Where("application_id = ?", application.ID).First(&inv).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	streamInvoice(c, &inv)
+}
+
+// GetInvoices lists issued invoices, newest first (admin only)
+func GetInvoices(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.Invoice{})
+	if financialYear := c.Query("financial_year"); financialYear != "" {
+		query = query.Where("financial_year = ?", financialYear)
+	}
+	if applicationID := c.Query("application_id"); applicationID != "" {
+		query = query.Where("application_id = ?", applicationID)
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := parseAuditTime(since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected YYYY-MM-DD or RFC 3339"})
+			return
+		}
+		query = query.Where("issued_at >= ?", t)
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := parseAuditTime(until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until, expected YYYY-MM-DD or RFC 3339"})
+			return
+		}
+		query = query.Where("issued_at < ?", t)
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	var invoices []models.Invoice
+	if err := query.Offset(offset).Limit(limit).Order("issued_at DESC, id DESC").Find(&invoices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"invoices": invoices,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// DownloadInvoice downloads an invoice's PDF (admin only)
+func DownloadInvoice(c *gin.Context) {
+	var inv models.Invoice
+	if err := database.DB.First(&inv, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	streamInvoice(c, &inv)
+}
+
+// CreateInvoice issues the invoice for a paid application whose invoice wasn't
+// issued automatically, e.g. because invoicing wasn't configured (admin only)
+func CreateInvoice(c *gin.Context) {
+	var req models.InvoiceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv, err := issueInvoice(c.Request.Context(), req.ApplicationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+	if errors.Is(err, errInvoiceNotPaid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only paid applications can be invoiced"})
+		return
+	}
+	if errors.Is(err, errInvoicingUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Invoicing is not configured"})
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to issue invoice for application %d: %v", req.ApplicationID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue invoice"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice": inv})
+}
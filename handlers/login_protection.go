@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Login attempt reasons
+const (
+	loginReasonInvalidCredentials = "invalid_credentials"
+	loginReasonAccountLocked      = "account_locked"
+	loginReasonIPBlocked          = "ip_blocked"
+	loginReasonDeactivated        = "deactivated"
+	loginReasonInvalidTwoFactor   = "invalid_two_factor_code"
+	loginReasonInvalidOTP         = "invalid_login_code"
+)
+
+// ipFailureReasons are the failures that count towards blocking an IP. Requests
+// rejected because of an existing block or lockout are recorded but not counted,
+// so retrying during a block doesn't extend it.
+var ipFailureReasons = []string{loginReasonInvalidCredentials, loginReasonInvalidTwoFactor, loginReasonInvalidOTP}
+
+// loginProtectionSettings holds the brute-force protection thresholds
+type loginProtectionSettings struct {
+	maxAttempts   int           // consecutive failures before an account is locked
+	lockoutBase   time.Duration // first lockout duration, doubled on each further failure
+	lockoutMax    time.Duration // upper bound for a lockout
+	ipMaxFailures int           // failures from one IP within ipWindow before it is blocked
+	ipWindow      time.Duration
+}
+
+// getLoginProtectionSettings reads brute-force protection settings from config
+func getLoginProtectionSettings() loginProtectionSettings {
+	loginConfig := config.GetLoginProtectionConfig()
+	settings := loginProtectionSettings{
+		maxAttempts:   5,
+		lockoutBase:   time.Minute,
+		lockoutMax:    time.Hour,
+		ipMaxFailures: 20,
+		ipWindow:      15 * time.Minute,
+	}
+
+	if v, err := strconv.Atoi(loginConfig["max_attempts"]); err == nil && v > 0 {
+		settings.maxAttempts = v
+	}
+	if v, err := time.ParseDuration(loginConfig["lockout_base"]); err == nil && v > 0 {
+		settings.lockoutBase = v
+	}
+	if v, err := time.ParseDuration(loginConfig["lockout_max"]); err == nil && v > 0 {
+		settings.lockoutMax = v
+	}
+	if v, err := strconv.Atoi(loginConfig["ip_max_failures"]); err == nil && v > 0 {
+		settings.ipMaxFailures = v
+	}
+	if v, err := time.ParseDuration(loginConfig["ip_window"]); err == nil && v > 0 {
+		settings.ipWindow = v
+	}
+
+	return settings
+}
+
+// lockoutDuration returns how long an account is locked after the given number of
+// consecutive failures, doubling for each failure past the threshold
+func (s loginProtectionSettings) lockoutDuration(failures int) time.Duration {
+	if failures < s.maxAttempts {
+		return 0
+	}
+	exponent := failures - s.maxAttempts
+	if exponent > 30 {
+		return s.lockoutMax
+	}
+	d := time.Duration(float64(s.lockoutBase) * math.Pow(2, float64(exponent)))
+	if d > s.lockoutMax {
+		return s.lockoutMax
+	}
+	return d
+}
+
+// recordLoginAttempt stores a login attempt event
+func recordLoginAttempt(c *gin.Context, userID *uint, email string, success bool, reason string) {
+	database.DB.Create(&models.LoginAttempt{
+		UserID:    userID,
+		Email:     email,
+		IPAddress: c.ClientIP(),
+		Success:   success,
+		Reason:    reason,
+	})
+}
+
+// ipBlockedFor reports how long the client IP must wait because of too many recent failures
+func ipBlockedFor(c *gin.Context, settings loginProtectionSettings) time.Duration {
+	var failures []models.LoginAttempt
+	database.DB.Select("created_at").
+		Where("ip_address = ? AND success = ? AND reason IN ? AND created_at > ?", c.ClientIP(), false, ipFailureReasons, time.Now().Add(-settings.ipWindow)).
+		Order("created_at DESC").
+		Limit(settings.ipMaxFailures).
+		Find(&failures)
+
+	if len(failures) < settings.ipMaxFailures {
+		return 0
+	}
+
+	// Blocked until the oldest counted failure falls out of the window
+	oldest := failures[len(failures)-1].CreatedAt
+	return time.Until(oldest.Add(settings.ipWindow))
+}
+
+// registerFailedLogin increments a user's consecutive failures and locks the
+// account when the threshold is reached. Returns the lockout duration, if any.
+func registerFailedLogin(user *models.User, settings loginProtectionSettings) time.Duration {
+	if err := database.DB.Model(user).Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+		return 0
+	}
+	database.DB.Select("failed_login_attempts").First(user, user.ID)
+
+	lockout := settings.lockoutDuration(user.FailedLoginAttempts)
+	if lockout > 0 {
+		lockedUntil := time.Now().Add(lockout)
+		database.DB.Model(user).Update("locked_until", lockedUntil)
+	}
+	return lockout
+}
+
+// resetFailedLogins clears a user's failure counter and lockout
+func resetFailedLogins(userID uint) error {
+	return database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error
+}
+
+// respondLocked writes a 429 response with a Retry-After header
+func respondLocked(c *gin.Context, message string, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"success":     false,
+		"message":     fmt.Sprintf("%s. Try again in %s", message, time.Duration(seconds)*time.Second),
+		"retry_after": seconds,
+	})
+}
+
+// GetUserLockout returns a user's login lockout state and recent attempts (admin only)
+func GetUserLockout(c *gin.Context) {
+	id := c.Param("id")
+
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var attempts []models.LoginAttempt
+	database.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Limit(20).Find(&attempts)
+
+	response := models.LockoutResponse{
+		UserID:              user.ID,
+		Email:               user.Email,
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		LockedUntil:         user.LockedUntil,
+		Locked:              user.LockedUntil != nil && time.Now().Before(*user.LockedUntil),
+		RecentAttempts:      attempts,
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lockout": response})
+}
+
+// ClearUserLockout resets a user's failed login counter and lockout (admin only)
+func ClearUserLockout(c *gin.Context) {
+	id := c.Param("id")
+
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := resetFailedLogins(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lockout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared successfully"})
+}
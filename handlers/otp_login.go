@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// otpLoginLimit reads a per-hour OTP login limit from config, falling back to def
+func otpLoginLimit(key string, def int) int {
+	limit, err := strconv.Atoi(config.GetVerificationConfig()[key])
+	if err != nil || limit <= 0 {
+		return def
+	}
+	return limit
+}
+
+// otpLoginLimiters holds the in-process rate limiters for passwordless login.
+// They are created on first use because config is loaded after package initialization.
+var otpLoginLimiters struct {
+	once  sync.Once
+	phone *utils.RateLimiter
+	ip    *utils.RateLimiter
+}
+
+// allowOTPLogin records a passwordless login request and reports whether it is
+// within the hourly limits for both the phone number and the client IP
+func allowOTPLogin(c *gin.Context, phone string) bool {
+	otpLoginLimiters.once.Do(func() {
+		otpLoginLimiters.phone = utils.NewRateLimiter(otpLoginLimit("otp_login_phone_limit", 5), time.Hour)
+		otpLoginLimiters.ip = utils.NewRateLimiter(otpLoginLimit("otp_login_ip_limit", 20), time.Hour)
+	})
+
+	// Check the IP first so a single client can't exhaust other users' phone quotas
+	return otpLoginLimiters.ip.Allow(c.FullPath()+":"+c.ClientIP()) &&
+		otpLoginLimiters.phone.Allow(c.FullPath()+":"+phone)
+}
+
+// RequestLoginOTP sends a login code to a registered phone number.
+// The response is the same whether or not the phone is registered.
+func RequestLoginOTP(c *gin.Context) {
+	var req models.OTPLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if !allowOTPLogin(c, req.Phone) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"message": "Too many login code requests, please try again later",
+		})
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"message": "If this phone number is registered, a login code has been sent",
+	}
+
+	var user models.User
+	if err := database.DB.Where("phone = ?", req.Phone).First(&user).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// A resend cooldown only applies to registered phones, so it gets the same response
+	if err := issueOTP(c.Request.Context(), &user.ID, models.OTPPurposeLogin, "phone", user.Phone); err != nil {
+		if err == errOTPResendTooSoon {
+			log.Printf("Login code for user %d requested again within the resend interval", user.ID)
+		} else {
+			log.Printf("Failed to send login code to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyLoginOTP exchanges a login code for the same tokens Login issues
+func VerifyLoginOTP(c *gin.Context) {
+	var req models.OTPLoginVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if !allowOTPLogin(c, req.Phone) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"message": "Too many login attempts, please try again later",
+		})
+		return
+	}
+
+	var user models.User
+	found := database.DB.Where("phone = ?", req.Phone).First(&user).Error == nil
+
+	// Login codes are subject to the same lockout as passwords
+	if found && user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		recordLoginAttempt(c, &user.ID, user.Email, false, loginReasonAccountLocked)
+		respondLocked(c, "Account temporarily locked due to failed login attempts", time.Until(*user.LockedUntil))
+		return
+	}
+
+	if _, err := verifyOTP(models.OTPPurposeLogin, req.Phone, req.Code); err != nil {
+		if found {
+			recordLoginAttempt(c, &user.ID, user.Email, false, loginReasonInvalidOTP)
+			if lockout := registerFailedLogin(&user, getLoginProtectionSettings()); lockout > 0 {
+				respondLocked(c, "Account temporarily locked due to failed login attempts", lockout)
+				return
+			}
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid credentials",
+		})
+		return
+	}
+
+	if !user.IsActive {
+		recordLoginAttempt(c, &user.ID, user.Email, false, loginReasonDeactivated)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Account is deactivated",
+		})
+		return
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		resetFailedLogins(user.ID)
+	}
+	recordLoginAttempt(c, &user.ID, user.Email, true, "")
+
+	// Receiving the code proves the user controls the phone
+	if user.PhoneVerifiedAt == nil {
+		now := time.Now()
+		if err := database.DB.Model(&user).Update("phone_verified_at", now).Error; err == nil {
+			user.PhoneVerifiedAt = &now
+			middleware.InvalidateUserCache(user.ID)
+		}
+	}
+
+	completeLogin(c, user)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/mailer"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// passwordResetExpiry returns how long password reset tokens remain valid
+func passwordResetExpiry() time.Duration {
+	expiry, err := time.ParseDuration(config.GetAuthConfig()["password_reset_expiry"])
+	if err != nil {
+		expiry = time.Hour // default to 1 hour
+	}
+	return expiry
+}
+
+// ForgotPassword issues a password reset token and emails it to the user.
+// The response is the same whether or not the email is registered.
+func ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"message": "If an account exists for this email, a password reset link has been sent",
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, err := utils.GenerateRandomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to create reset token",
+		})
+		return
+	}
+
+	expiry := passwordResetExpiry()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the most recent reset token is usable
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+
+		resetToken := models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(expiry),
+		}
+		return tx.Create(&resetToken).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to create reset token",
+		})
+		return
+	}
+
+	resetURL := config.GetAuthConfig()["password_reset_url"]
+	if resetURL == "" {
+		resetURL = "http://localhost:3000/reset-password"
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Bharat Seva Space password",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"We received a request to reset your password. Use the link below to choose a new one:\n\n"+
+			"%s?token=%s\n\n"+
+			"This link expires in %s and can only be used once. If you did not request a reset, you can ignore this email.\n",
+			user.Name, resetURL, url.QueryEscape(token), expiry),
+	}
+
+	// Send in the background so response time doesn't reveal whether the account exists
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mailer.Default.Send(ctx, msg); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password using a password reset token
+func ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var resetToken models.PasswordResetToken
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(req.Token)).First(&resetToken).Error; err != nil ||
+		resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid or expired reset token",
+		})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to hash password",
+		})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Mark the token used first so a concurrent request can't use it twice
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", resetToken.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Proving control of the email also lifts a lockout from failed logins,
+		// and the new password counts as the required change
+		return tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Updates(map[string]interface{}{
+			"password":              hashedPassword,
+			"must_change_password":  false,
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": "Invalid or expired reset token",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to reset password",
+		})
+		return
+	}
+
+	// Sign out everywhere after a password reset
+	revokeUserSessions(resetToken.UserID)
+	middleware.InvalidateUserCache(resetToken.UserID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset successfully",
+	})
+}
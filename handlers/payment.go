@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+	"bharat-seva-space/payments"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxWebhookBodySize caps the size of a webhook body
+const maxWebhookBodySize = 1 << 20
+
+// CreatePaymentOrder creates a gateway order for the application's amount. An unpaid
+// order for the same amount is reused so retrying checkout doesn't create new orders.
+func CreatePaymentOrder(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	gateway := payments.Default
+	if gateway == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Online payments are not enabled"})
+		return
+	}
+
+	// Only the applicant pays for an application
+	var application models.Application
+	if err := database.DB.Where("user_id = ?", currentUser.ID).First(&application, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	if application.Status == models.ApplicationStatusCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Application is cancelled"})
+		return
+	}
+	if application.PaymentStatus != models.PaymentStatusPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Application is already paid"})
+		return
+	}
+	if application.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Application has nothing to pay"})
+		return
+	}
+
+	var payment models.Payment
+	err := database.DB.Where("application_id = ? AND status = ? AND amount = ? AND gateway = ?",
+		application.ID, models.PaymentOrderCreated, application.Amount, gateway.Name()).
+		Order("created_at DESC").First(&payment).Error
+	if err == nil {
+		c.JSON(http.StatusOK, paymentOrderResponse(payment, gateway))
+		return
+	}
+
+	order, err := gateway.CreateOrder(c.Request.Context(), payments.ToPaise(application.Amount), models.PaymentCurrency,
+		fmt.Sprintf("application-%d", application.ID),
+		map[string]string{"application_id": strconv.FormatUint(uint64(application.ID), 10)})
+	if err != nil {
+		log.Printf("Failed to create payment order for application %d: %v", application.ID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to create payment order"})
+		return
+	}
+
+	payment = models.Payment{
+		ApplicationID: application.ID,
+		Gateway:       gateway.Name(),
+		OrderID:       order.ID,
+		Amount:        application.Amount,
+		Currency:      models.PaymentCurrency,
+		Status:        models.PaymentOrderCreated,
+	}
+	if err := database.DB.Create(&payment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save payment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, paymentOrderResponse(payment, gateway))
+}
+
+// paymentOrderResponse returns what the client needs to open the checkout for a payment
+func paymentOrderResponse(payment models.Payment, gateway payments.Gateway) models.PaymentOrderResponse {
+	return models.PaymentOrderResponse{
+		Payment:  payment,
+		KeyID:    gateway.KeyID(),
+		OrderID:  payment.OrderID,
+		Amount:   payments.ToPaise(payment.Amount),
+		Currency: payment.Currency,
+	}
+}
+
+// GetApplicationPayments lists an application's payment orders, newest first
+func GetApplicationPayments(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionPaymentsRead)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	var applicationPayments []models.Payment
+	if err := database.DB.Where("application_id = ?", application.ID).Order("created_at DESC, id DESC").Find(&applicationPayments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payments": applicationPayments})
+}
+
+// GetPayments lists payment orders, newest first (admin only)
+func GetPayments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.Payment{})
+	if applicationID := c.Query("application_id"); applicationID != "" {
+		query = query.Where("application_id = ?", applicationID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	var results []models.Payment
+	if err := query.Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"payments": results,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// PaymentWebhook receives payment events from the gateway. The body must carry a valid
+// HMAC signature. Each event is processed once; redeliveries are acknowledged without
+// changes, and processing errors return 500 so the gateway retries.
+func PaymentWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize+1))
+	if err != nil || len(body) > maxWebhookBodySize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook body"})
+		return
+	}
+
+	secret := config.GetPaymentConfig()["webhook_secret"]
+	if !payments.VerifyWebhookSignature(body, c.GetHeader(payments.SignatureHeader), secret) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	var event payments.WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Event == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook body"})
+		return
+	}
+
+	// Fall back to the body hash when the gateway doesn't send an event ID
+	eventID := c.GetHeader(payments.EventIDHeader)
+	if eventID == "" {
+		sum := sha256.Sum256(body)
+		eventID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	entity := event.Payload.Payment.Entity
+	duplicate := false
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		record := models.PaymentWebhookEvent{
+			EventID: eventID,
+			Event:   event.Event,
+			OrderID: entity.OrderID,
+			Payload: string(body),
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			duplicate = true
+			return nil
+		}
+
+		switch event.Event {
+		case payments.EventPaymentCaptured, payments.EventOrderPaid:
+			return markPaymentPaid(tx, entity)
+		case payments.EventPaymentFailed:
+			return markPaymentFailed(tx, entity)
+		case payments.EventRefundProcessed, payments.EventRefundFailed:
+			return settleWebhookRefund(tx, event.Event, event.Payload.Refund.Entity)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to process payment webhook %s: %v", eventID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	if duplicate {
+		c.JSON(http.StatusOK, gin.H{"message": "Event already processed"})
+		return
+	}
+
+	if event.Event == payments.EventPaymentCaptured || event.Event == payments.EventOrderPaid {
+		var payment models.Payment
+		if err := database.DB.Where("order_id = ?", entity.OrderID).First(&payment).Error; err == nil {
+			issuePaidInvoice(c.Request.Context(), payment.ApplicationID)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Event processed"})
+}
+
+// findWebhookPayment loads the payment for a webhook's order. Unknown orders are
+// logged and skipped so the gateway stops retrying them.
+func findWebhookPayment(tx *gorm.DB, entity payments.PaymentEntity) (*models.Payment, error) {
+	var payment models.Payment
+	err := tx.Where("order_id = ?", entity.OrderID).First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Payment webhook for unknown order %q", entity.OrderID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// markPaymentPaid settles a payment and its application. Both updates are guarded
+// on the previous status, so a repeated or late event changes nothing.
+func markPaymentPaid(tx *gorm.DB, entity payments.PaymentEntity) error {
+	payment, err := findWebhookPayment(tx, entity)
+	if err != nil || payment == nil {
+		return err
+	}
+	if entity.Amount != payments.ToPaise(payment.Amount) {
+		log.Printf("Payment webhook amount %d for order %q doesn't match %.2f; not marking paid", entity.Amount, entity.OrderID, payment.Amount)
+		return nil
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.Payment{}).
+		Where("id = ? AND status <> ?", payment.ID, models.PaymentOrderPaid).
+		Updates(map[string]interface{}{
+			"status":             models.PaymentOrderPaid,
+			"gateway_payment_id": entity.ID,
+			"method":             entity.Method,
+			"failure_reason":     "",
+			"paid_at":            now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	result = tx.Model(&models.Application{}).
+		Where("id = ? AND payment_status = ?", payment.ApplicationID, models.PaymentStatusPending).
+		Update("payment_status", models.PaymentStatusPaid)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	before := &models.Application{ID: payment.ApplicationID, PaymentStatus: models.PaymentStatusPending}
+	return recordApplicationChanges(tx, before, map[string]interface{}{"payment_status": models.PaymentStatusPaid}, nil)
+}
+
+// markPaymentFailed records a failed attempt on an order that hasn't been paid
+func markPaymentFailed(tx *gorm.DB, entity payments.PaymentEntity) error {
+	payment, err := findWebhookPayment(tx, entity)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	return tx.Model(&models.Payment{}).
+		Where("id = ? AND status = ?", payment.ID, models.PaymentOrderCreated).
+		Updates(map[string]interface{}{
+			"status":             models.PaymentOrderFailed,
+			"gateway_payment_id": entity.ID,
+			"method":             entity.Method,
+			"failure_reason":     entity.ErrorDescription,
+		}).Error
+}
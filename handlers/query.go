@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
 	"bharat-seva-space/models"
 
 	"github.com/gin-gonic/gin"
@@ -18,14 +20,20 @@ func CreateQuery(c *gin.Context) {
 		return
 	}
 
+	service, err := findActiveService(req.Service)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown or inactive service"})
+		return
+	}
+
 	// Create query
 	query := models.Query{
 		Name:    req.Name,
 		Email:   req.Email,
 		Phone:   req.Phone,
-		Service: req.Service,
+		Service: service.Name,
 		Message: req.Message,
-		Status:  "new",
+		Status:  models.QueryStatusNew,
 	}
 
 	if err := database.DB.Create(&query).Error; err != nil {
@@ -164,10 +172,22 @@ func UpdateQuery(c *gin.Context) {
 		return
 	}
 
+	before := map[string]interface{}{"status": query.Status, "assigned_to": query.AssignedTo, "notes": query.Notes}
+
 	// Update query
 	updates := make(map[string]interface{})
+	// A status change only applies if the row still holds the status it was validated against
+	guard := database.DB.Model(&query)
 	if req.Status != "" {
+		if err := models.QueryStatusTransitions.Validate(query.Status, req.Status); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":         fmt.Sprintf("Invalid status change: %v", err),
+				"next_statuses": models.QueryStatusTransitions.Next(query.Status),
+			})
+			return
+		}
 		updates["status"] = req.Status
+		guard = guard.Where("status = ?", query.Status)
 	}
 	if req.AssignedTo != nil {
 		updates["assigned_to"] = req.AssignedTo
@@ -176,10 +196,16 @@ func UpdateQuery(c *gin.Context) {
 		updates["notes"] = req.Notes
 	}
 
-	if err := database.DB.Model(&query).Updates(updates).Error; err != nil {
+	result := guard.Updates(updates)
+	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update query"})
 		return
 	}
+	if len(updates) > 0 && result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Query was changed by someone else, reload and try again"})
+		return
+	}
+	middleware.SetAuditChanges(c, before, updates)
 
 	// Get updated query with relationships
 	database.DB.Preload("AssignedUser").First(&query, id)
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+	"bharat-seva-space/payments"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reconciliation issues
+const (
+	issueNoGatewayPayment = "no_gateway_payment" // Marked paid or refunded without a gateway payment, e.g. paid offline
+	issueUnappliedPayment = "unapplied_payment"  // Payment status pending although money was received
+	issueUnderpaid        = "underpaid"
+	issueOverpaid         = "overpaid"
+	issueRefundIncomplete = "refund_incomplete" // Marked refunded while part of the payment is kept
+)
+
+// reconcileApplication fills in the expected net amount and any issues for a row.
+// A paid application should have received its amount, less any refunds; pending
+// and refunded applications should hold nothing.
+func reconcileApplication(row *models.ReconciliationRow) {
+	row.Net = roundRupees(row.Paid - row.Refunded)
+	row.Issues = []string{}
+
+	switch row.PaymentStatus {
+	case models.PaymentStatusPaid:
+		row.Expected = roundRupees(row.Amount - row.Refunded)
+		if row.Paid == 0 {
+			row.Issues = append(row.Issues, issueNoGatewayPayment)
+		} else if payments.ToPaise(row.Paid) < payments.ToPaise(row.Amount) {
+			row.Issues = append(row.Issues, issueUnderpaid)
+		} else if payments.ToPaise(row.Paid) > payments.ToPaise(row.Amount) {
+			row.Issues = append(row.Issues, issueOverpaid)
+		}
+	case models.PaymentStatusRefunded:
+		row.Expected = 0
+		if row.Paid == 0 {
+			row.Issues = append(row.Issues, issueNoGatewayPayment)
+		} else if payments.ToPaise(row.Net) > 0 {
+			row.Issues = append(row.Issues, issueRefundIncomplete)
+		}
+	default:
+		row.Expected = 0
+		if payments.ToPaise(row.Net) > 0 {
+			row.Issues = append(row.Issues, issueUnappliedPayment)
+		}
+	}
+	row.Difference = roundRupees(row.Net - row.Expected)
+}
+
+// GetPaymentReconciliation compares each application's amount and payment status with
+// its paid gateway payments and processed refunds (admin only). Only applications with
+// issues are listed unless all=true; format=csv downloads the rows.
+func GetPaymentReconciliation(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be csv or json"})
+		return
+	}
+
+	query := database.DB.Table("applications").
+		Select(`applications.id AS application_id, applications.user_id, applications.service_type,
+			applications.payment_status, applications.amount,
+			COALESCE(paid.total, 0) AS paid, COALESCE(refunded.total, 0) AS refunded`).
+		Joins(`LEFT JOIN (SELECT application_id, SUM(amount) AS total FROM payments WHERE status IN (?, ?) GROUP BY application_id) AS paid ON paid.application_id = applications.id`,
+			models.PaymentOrderPaid, models.PaymentOrderRefunded).
+		Joins(`LEFT JOIN (SELECT application_id, SUM(amount) AS total FROM refunds WHERE status = ? GROUP BY application_id) AS refunded ON refunded.application_id = applications.id`,
+			models.RefundStatusProcessed).
+		Where("applications.deleted_at IS NULL")
+
+	if since := c.Query("since"); since != "" {
+		t, err := parseAuditTime(since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected YYYY-MM-DD or RFC 3339"})
+			return
+		}
+		query = query.Where("applications.created_at >= ?", t)
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := parseAuditTime(until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until, expected YYYY-MM-DD or RFC 3339"})
+			return
+		}
+		query = query.Where("applications.created_at < ?", t)
+	}
+
+	var rows []models.ReconciliationRow
+	if err := query.Order("applications.id").Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build reconciliation report"})
+		return
+	}
+
+	all := c.Query("all") == "true"
+	var expected, paid, refunded float64
+	issues := 0
+	report := make([]models.ReconciliationRow, 0, len(rows))
+	for _, row := range rows {
+		reconcileApplication(&row)
+		expected += row.Expected
+		paid += row.Paid
+		refunded += row.Refunded
+		if len(row.Issues) > 0 {
+			issues++
+		}
+		if all || len(row.Issues) > 0 {
+			report = append(report, row)
+		}
+	}
+
+	if format == "csv" {
+		fileName := fmt.Sprintf("reconciliation-%s.csv", time.Now().Format("20060102-150405"))
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+		writer := csv.NewWriter(c.Writer)
+		writer.Write([]string{"application_id", "user_id", "service_type", "payment_status", "amount", "paid", "refunded", "net", "expected", "difference", "issues"})
+		for _, row := range report {
+			writer.Write([]string{
+				strconv.FormatUint(uint64(row.ApplicationID), 10),
+				strconv.FormatUint(uint64(row.UserID), 10),
+				row.ServiceType,
+				row.PaymentStatus,
+				strconv.FormatFloat(row.Amount, 'f', 2, 64),
+				strconv.FormatFloat(row.Paid, 'f', 2, 64),
+				strconv.FormatFloat(row.Refunded, 'f', 2, 64),
+				strconv.FormatFloat(row.Net, 'f', 2, 64),
+				strconv.FormatFloat(row.Expected, 'f', 2, 64),
+				strconv.FormatFloat(row.Difference, 'f', 2, 64),
+				strings.Join(row.Issues, ";"),
+			})
+		}
+		writer.Flush()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"applications": report,
+		"summary": gin.H{
+			"applications": len(rows),
+			"with_issues":  issues,
+			"expected":     roundRupees(expected),
+			"paid":         roundRupees(paid),
+			"refunded":     roundRupees(refunded),
+			"net":          roundRupees(paid - refunded),
+		},
+	})
+}
@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/payments"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errRefundConflict = errors.New("refund changed concurrently")
+
+// refundExceedsPaymentError is returned when a refund request is larger than what is left to refund
+type refundExceedsPaymentError struct {
+	refundable float64
+}
+
+func (e *refundExceedsPaymentError) Error() string {
+	return fmt.Sprintf("refund exceeds the refundable amount of %.2f", e.refundable)
+}
+
+// roundRupees rounds an amount to the paisa
+func roundRupees(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// createRefundRequest records a refund request against a paid payment. The payment row
+// is locked so concurrent requests can't reserve more than the payment amount.
+func createRefundRequest(payment *models.Payment, req models.RefundCreateRequest, requestedBy uint) (*models.Refund, error) {
+	refund := models.Refund{
+		PaymentID:     payment.ID,
+		ApplicationID: payment.ApplicationID,
+		Amount:        roundRupees(req.Amount),
+		Reason:        req.Reason,
+		Status:        models.RefundStatusRequested,
+		RequestedBy:   requestedBy,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, payment.ID).Error; err != nil {
+			return err
+		}
+
+		var reserved float64
+		if err := tx.Model(&models.Refund{}).
+			Where("payment_id = ? AND status IN ?", payment.ID, models.RefundOpenStatuses).
+			Select("COALESCE(SUM(amount), 0)").Scan(&reserved).Error; err != nil {
+			return err
+		}
+
+		refundable := roundRupees(locked.Amount - reserved)
+		if payments.ToPaise(refund.Amount) > payments.ToPaise(refundable) {
+			return &refundExceedsPaymentError{refundable: refundable}
+		}
+		return tx.Create(&refund).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// respondRefundRequest validates and records a refund request for a payment of the application
+func respondRefundRequest(c *gin.Context, applicationID uint, req models.RefundCreateRequest, requestedBy uint) {
+	// Amounts are refunded in whole paise, so anything that rounds to zero is rejected
+	if payments.ToPaise(req.Amount) <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refund amount must be at least 0.01"})
+		return
+	}
+
+	var payment models.Payment
+	if err := database.DB.Where("application_id = ?", applicationID).First(&payment, req.PaymentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	}
+	if payment.Status != models.PaymentOrderPaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only paid payments can be refunded"})
+		return
+	}
+
+	refund, err := createRefundRequest(&payment, req, requestedBy)
+	if err != nil {
+		var exceeds *refundExceedsPaymentError
+		if errors.As(err, &exceeds) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Refund exceeds the refundable amount", "refundable": exceeds.refundable})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request refund"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Refund requested successfully",
+		"refund":  refund,
+	})
+}
+
+// CreateRefundRequest lets an applicant ask for a refund of one of their payments
+func CreateRefundRequest(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.RefundCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var application models.Application
+	if err := database.DB.Where("user_id = ?", currentUser.ID).First(&application, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	respondRefundRequest(c, application.ID, req, currentUser.ID)
+}
+
+// CreateStaffRefundRequest records a refund request on a customer's behalf (admin only)
+func CreateStaffRefundRequest(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.RefundCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payment models.Payment
+	if err := database.DB.First(&payment, req.PaymentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	}
+
+	respondRefundRequest(c, payment.ApplicationID, req, currentUser.ID)
+}
+
+// GetApplicationRefunds lists an application's refunds, newest first
+func GetApplicationRefunds(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	application, err := findAccessibleApplication(currentUser, id, models.PermissionPaymentsRead)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	var refunds []models.Refund
+	if err := database.DB.Where("application_id = ?", application.ID).Order("created_at DESC, id DESC").Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch refunds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refunds": refunds})
+}
+
+// GetRefunds lists refunds, newest first (admin only)
+func GetRefunds(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.Refund{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if applicationID := c.Query("application_id"); applicationID != "" {
+		query = query.Where("application_id = ?", applicationID)
+	}
+	if paymentID := c.Query("payment_id"); paymentID != "" {
+		query = query.Where("payment_id = ?", paymentID)
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	var refunds []models.Refund
+	if err := query.Preload("Payment").Offset(offset).Limit(limit).Order("created_at DESC, id DESC").Find(&refunds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch refunds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"refunds": refunds,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// reviewRefund moves a requested refund to approved or rejected, guarded on it still being requested
+func reviewRefund(refund *models.Refund, status string, reviewer uint, note string) error {
+	now := time.Now()
+	result := database.DB.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, models.RefundStatusRequested).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewer,
+			"review_note": note,
+			"reviewed_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errRefundConflict
+	}
+
+	refund.Status = status
+	refund.ReviewedBy = &reviewer
+	refund.ReviewNote = note
+	refund.ReviewedAt = &now
+	return nil
+}
+
+// ApproveRefund approves a refund request and sends it to the gateway (admin only)
+func ApproveRefund(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	// The note is optional, so an empty body is accepted
+	var req models.RefundReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	gateway := payments.Default
+	if gateway == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Online payments are not enabled"})
+		return
+	}
+
+	var refund models.Refund
+	if err := database.DB.Preload("Payment").First(&refund, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Refund not found"})
+		return
+	}
+	// An approved refund the gateway never confirmed can be approved again to
+	// resend it; the gateway recognises the receipt, so it isn't paid twice
+	resend := refund.Status == models.RefundStatusApproved && refund.GatewayRefundID == ""
+	if refund.Status != models.RefundStatusRequested && !resend {
+		c.JSON(http.StatusConflict, gin.H{"error": "Refund is not awaiting review"})
+		return
+	}
+	if refund.Payment.Gateway != gateway.Name() || refund.Payment.GatewayPaymentID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment can't be refunded through the current gateway"})
+		return
+	}
+
+	if !resend {
+		if err := reviewRefund(&refund, models.RefundStatusApproved, currentUser.ID, req.Note); err != nil {
+			if err == errRefundConflict {
+				c.JSON(http.StatusConflict, gin.H{"error": "Refund is not awaiting review"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve refund"})
+			return
+		}
+		middleware.SetAuditChanges(c, map[string]interface{}{"status": models.RefundStatusRequested}, map[string]interface{}{"status": models.RefundStatusApproved})
+	}
+
+	refundID := strconv.FormatUint(uint64(refund.ID), 10)
+	result, err := gateway.Refund(c.Request.Context(), refund.Payment.GatewayPaymentID, payments.ToPaise(refund.Amount), "refund_"+refundID, map[string]string{
+		"refund_id":      refundID,
+		"application_id": strconv.FormatUint(uint64(refund.ApplicationID), 10),
+	})
+	if err != nil && payments.IsRejected(err) {
+		log.Printf("Gateway rejected refund %d: %v", refund.ID, err)
+		failRefund(database.DB, &refund, err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Gateway refund failed"})
+		return
+	}
+	if err != nil {
+		// The gateway may have made the refund anyway, so it stays approved and
+		// keeps its amount reserved until a retry or the webhook settles it
+		log.Printf("Gateway refund for refund %d has an unknown outcome: %v", refund.ID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Gateway refund status is unknown, approve the refund again to retry"})
+		return
+	}
+
+	// Recorded on its own first so the gateway's webhooks can find the refund
+	// even if settling it below fails
+	if err := database.DB.Model(&models.Refund{}).Where("id = ?", refund.ID).Update("gateway_refund_id", result.ID).Error; err != nil {
+		log.Printf("Failed to record gateway refund %s for refund %d: %v", result.ID, refund.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Refund was sent to the gateway but could not be recorded"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		switch result.Status {
+		case payments.RefundProcessed:
+			return applyProcessedRefund(tx, &refund)
+		case payments.RefundFailed:
+			return failRefund(tx, &refund, "Refund failed at the gateway")
+		}
+		// Pending refunds are settled by the refund.processed or refund.failed webhook
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to settle gateway refund %s for refund %d: %v", result.ID, refund.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Refund was sent to the gateway but could not be recorded"})
+		return
+	}
+
+	database.DB.Preload("Payment").First(&refund, refund.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund approved successfully",
+		"refund":  refund,
+	})
+}
+
+// RejectRefund rejects a refund request (admin only)
+func RejectRefund(c *gin.Context) {
+	id := c.Param("id")
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	// The note is optional, so an empty body is accepted
+	var req models.RefundReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var refund models.Refund
+	if err := database.DB.First(&refund, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Refund not found"})
+		return
+	}
+
+	if err := reviewRefund(&refund, models.RefundStatusRejected, currentUser.ID, req.Note); err != nil {
+		if err == errRefundConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Refund is not awaiting review"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject refund"})
+		return
+	}
+	middleware.SetAuditChanges(c, map[string]interface{}{"status": models.RefundStatusRequested}, map[string]interface{}{"status": models.RefundStatusRejected})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Refund rejected",
+		"refund":  refund,
+	})
+}
+
+// applyProcessedRefund marks an approved refund processed and adds it to the payment.
+// A payment refunded in full becomes refunded, and once none of the application's
+// payments remain paid the application's payment_status moves to refunded.
+func applyProcessedRefund(tx *gorm.DB, refund *models.Refund) error {
+	now := time.Now()
+	result := tx.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, models.RefundStatusApproved).
+		Updates(map[string]interface{}{"status": models.RefundStatusProcessed, "processed_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	if err := tx.Model(&models.Payment{}).Where("id = ?", refund.PaymentID).
+		Update("refunded_amount", gorm.Expr("refunded_amount + ?", refund.Amount)).Error; err != nil {
+		return err
+	}
+
+	var payment models.Payment
+	if err := tx.First(&payment, refund.PaymentID).Error; err != nil {
+		return err
+	}
+	if payments.ToPaise(payment.RefundedAmount) < payments.ToPaise(payment.Amount) {
+		return nil
+	}
+	if err := tx.Model(&models.Payment{}).
+		Where("id = ? AND status = ?", payment.ID, models.PaymentOrderPaid).
+		Update("status", models.PaymentOrderRefunded).Error; err != nil {
+		return err
+	}
+
+	var stillPaid int64
+	if err := tx.Model(&models.Payment{}).
+		Where("application_id = ? AND status = ?", payment.ApplicationID, models.PaymentOrderPaid).
+		Count(&stillPaid).Error; err != nil {
+		return err
+	}
+	if stillPaid > 0 {
+		return nil
+	}
+
+	result = tx.Model(&models.Application{}).
+		Where("id = ? AND payment_status = ?", payment.ApplicationID, models.PaymentStatusPaid).
+		Update("payment_status", models.PaymentStatusRefunded)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	before := &models.Application{ID: payment.ApplicationID, PaymentStatus: models.PaymentStatusPaid}
+	return recordApplicationChanges(tx, before, map[string]interface{}{"payment_status": models.PaymentStatusRefunded}, refund.ReviewedBy)
+}
+
+// failRefund marks an approved refund failed, releasing its amount for a new request
+func failRefund(tx *gorm.DB, refund *models.Refund, reason string) error {
+	return tx.Model(&models.Refund{}).
+		Where("id = ? AND status = ?", refund.ID, models.RefundStatusApproved).
+		Updates(map[string]interface{}{"status": models.RefundStatusFailed, "failure_reason": reason}).Error
+}
+
+// settleWebhookRefund applies a refund.processed or refund.failed webhook. Refunds
+// the application doesn't know about, such as ones made in the gateway dashboard, are skipped.
+func settleWebhookRefund(tx *gorm.DB, event string, entity payments.Refund) error {
+	var refund models.Refund
+	err := tx.Where("gateway_refund_id = ?", entity.ID).First(&refund).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && entity.Notes["refund_id"] != "" {
+		// The gateway refund ID wasn't recorded when the refund was sent, but the
+		// refund's own ID travels in its notes
+		err = tx.Where("id = ? AND gateway_refund_id = ''", entity.Notes["refund_id"]).First(&refund).Error
+		if err == nil {
+			err = tx.Model(&refund).Update("gateway_refund_id", entity.ID).Error
+		}
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Refund webhook for unknown refund %q", entity.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if event == payments.EventRefundFailed {
+		return failRefund(tx, &refund, "Refund failed at the gateway")
+	}
+	return applyProcessedRefund(tx, &refund)
+}
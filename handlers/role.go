@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var (
+	errRoleNotFound = errors.New("role does not exist")
+	errLastAdmin    = errors.New("cannot remove the last admin")
+)
+
+// validatePermissions checks that every permission is known
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		known := false
+		for _, p := range models.AllPermissions {
+			if p == permission {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+	return nil
+}
+
+// validateRoleChange checks that a user may be moved to a role: the role must
+// exist and the last active admin can't be demoted
+func validateRoleChange(user *models.User, roleName string) error {
+	var count int64
+	database.DB.Model(&models.Role{}).Where("name = ?", roleName).Count(&count)
+	if count == 0 {
+		return errRoleNotFound
+	}
+
+	if roleName != models.RoleAdmin {
+		return ensureOtherActiveAdmin(user)
+	}
+
+	return nil
+}
+
+// ensureOtherActiveAdmin returns errLastAdmin when user is the only active admin,
+// so they can't be demoted or deactivated
+func ensureOtherActiveAdmin(user *models.User) error {
+	if user.Role != models.RoleAdmin || !user.IsActive {
+		return nil
+	}
+
+	var admins int64
+	database.DB.Model(&models.User{}).Where("role = ? AND is_active = ?", models.RoleAdmin, true).Count(&admins)
+	if admins <= 1 {
+		return errLastAdmin
+	}
+	return nil
+}
+
+// GetRoles returns all roles and the permissions that can be granted (admin only)
+func GetRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := database.DB.Order("id").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"roles":       roles,
+		"permissions": models.AllPermissions,
+	})
+}
+
+// CreateRole creates a custom role (admin only)
+func CreateRole(c *gin.Context) {
+	var req models.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !roleNamePattern.MatchString(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role name must be lowercase letters, digits and underscores"})
+		return
+	}
+	if err := validatePermissions(req.Permissions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.Role{}).Where("name = ?", req.Name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role already exists"})
+		return
+	}
+
+	role := models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}
+	if err := database.DB.Create(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"role":    role,
+	})
+}
+
+// UpdateRole changes a role's description or permissions (admin only)
+func UpdateRole(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var role models.Role
+	if err := database.DB.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	// Editing the admin role could lock everyone out of the admin API
+	if role.Name == models.RoleAdmin && req.Permissions != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "The admin role's permissions cannot be changed"})
+		return
+	}
+
+	before := map[string]interface{}{"description": role.Description, "permissions": role.Permissions}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		if err := validatePermissions(req.Permissions); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		role.Permissions = req.Permissions
+	}
+
+	if err := database.DB.Save(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	middleware.InvalidateRoleCache()
+	middleware.SetAuditChanges(c, before, map[string]interface{}{"description": role.Description, "permissions": role.Permissions})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"role":    role,
+	})
+}
+
+// DeleteRole deletes a custom role that no user holds (admin only)
+func DeleteRole(c *gin.Context) {
+	id := c.Param("id")
+
+	var role models.Role
+	if err := database.DB.First(&role, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	if role.IsSystem {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	var holders int64
+	database.DB.Model(&models.User{}).Where("role = ?", role.Name).Count(&holders)
+	if holders > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Role is assigned to %d user(s)", holders)})
+		return
+	}
+
+	if err := database.DB.Delete(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+	middleware.InvalidateRoleCache()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// AssignUserRole assigns a role to a user and signs them out (admin only)
+func AssignUserRole(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.RoleAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.Role == req.Role {
+		c.JSON(http.StatusOK, gin.H{"message": "User already has this role"})
+		return
+	}
+
+	if err := validateRoleChange(&user, req.Role); err != nil {
+		status := http.StatusBadRequest
+		if err == errLastAdmin {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	previousRole := user.Role
+	if err := database.DB.Model(&user).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign role"})
+		return
+	}
+	middleware.InvalidateUserCache(user.ID)
+	middleware.SetAuditChanges(c, map[string]interface{}{"role": previousRole}, map[string]interface{}{"role": req.Role})
+
+	// Existing sessions were issued for the old role
+	if err := revokeUserSessions(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Role assigned successfully",
+		"role":        req.Role,
+		"permissions": middleware.UserPermissions(&user),
+	})
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var serviceSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slugify derives a URL slug from a service name
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// findActiveService looks up an active service by slug or, for older clients, by name
+func findActiveService(ref string) (*models.Service, error) {
+	var service models.Service
+	ref = strings.TrimSpace(ref)
+	err := database.DB.Where("is_active = ?", true).
+		Where("slug = ? OR LOWER(name) = LOWER(?)", ref, ref).
+		First(&service).Error
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// serviceTaken reports whether another service already uses the name or slug
+func serviceTaken(name, slug string, exceptID uint) bool {
+	var count int64
+	database.DB.Model(&models.Service{}).
+		Where("(LOWER(name) = LOWER(?) OR slug = ?) AND id <> ?", name, slug, exceptID).
+		Count(&count)
+	return count > 0
+}
+
+// requirementNames lists requirement names in checklist order
+func requirementNames(requirements []models.DocumentRequirement) []string {
+	names := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		names = append(names, requirement.Name)
+	}
+	return names
+}
+
+// serviceFieldValues returns a service's editable fields by column name
+func serviceFieldValues(service *models.Service) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                  service.Name,
+		"slug":                  service.Slug,
+		"description":           service.Description,
+		"base_price":            service.BasePrice,
+		"gst_rate":              service.GSTRate,
+		"document_requirements": requirementNames(service.DocumentRequirements),
+		"form_schema":           service.FormSchema,
+		"sla_days":              service.SLADays,
+		"is_active":             service.IsActive,
+	}
+}
+
+// GetServices returns the active service catalog for the website
+func GetServices(c *gin.Context) {
+	var services []models.Service
+	if err := database.DB.Preload("DocumentRequirements", orderRequirements).Where("is_active = ?", true).Order("name").Find(&services).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch services"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"services": services})
+}
+
+// GetService returns an active service by slug
+func GetService(c *gin.Context) {
+	var service models.Service
+	if err := database.DB.Preload("DocumentRequirements", orderRequirements).Where("slug = ? AND is_active = ?", c.Param("slug"), true).First(&service).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"service": service})
+}
+
+// GetAllServices returns every service, including inactive ones (admin only)
+func GetAllServices(c *gin.Context) {
+	var services []models.Service
+	if err := database.DB.Preload("DocumentRequirements", orderRequirements).Order("name").Find(&services).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch services"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"services": services})
+}
+
+// CreateService adds a service to the catalog (admin only)
+func CreateService(c *gin.Context) {
+	var req models.ServiceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	service := models.Service{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        req.Slug,
+		Description: req.Description,
+		BasePrice:   req.BasePrice,
+		GSTRate:     models.DefaultGSTRate,
+		SLADays:     req.SLADays,
+		IsActive:    true,
+	}
+	if service.Slug == "" {
+		service.Slug = slugify(service.Name)
+	}
+	if req.GSTRate != nil {
+		service.GSTRate = *req.GSTRate
+	}
+	if req.IsActive != nil {
+		service.IsActive = *req.IsActive
+	}
+
+	requirements, err := buildDocumentRequirements(req.DocumentRequirements)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	service.DocumentRequirements = requirements
+
+	if req.FormSchema != nil {
+		if err := req.FormSchema.Check(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		service.FormSchema = req.FormSchema
+	}
+
+	if !serviceSlugPattern.MatchString(service.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must be lowercase letters and digits separated by hyphens"})
+		return
+	}
+	if serviceTaken(service.Name, service.Slug, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A service with this name or slug already exists"})
+		return
+	}
+
+	// Creating the service also creates its requirements
+	if err := database.DB.Create(&service).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service"})
+		return
+	}
+	// is_active defaults to true in the database, so a false value has to be written explicitly
+	if !service.IsActive {
+		database.DB.Model(&service).Update("is_active", false)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Service created successfully",
+		"service": service,
+	})
+}
+
+// UpdateService changes a service (admin only). Existing applications keep the
+// price they were created with.
+func UpdateService(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.ServiceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var service models.Service
+	if err := database.DB.Preload("DocumentRequirements", orderRequirements).First(&service, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	before := serviceFieldValues(&service)
+
+	// Select the changed columns so zero values are written too
+	var columns []string
+	if req.Name != nil {
+		service.Name = strings.TrimSpace(*req.Name)
+		columns = append(columns, "name")
+	}
+	if req.Slug != nil {
+		if !serviceSlugPattern.MatchString(*req.Slug) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Slug must be lowercase letters and digits separated by hyphens"})
+			return
+		}
+		service.Slug = *req.Slug
+		columns = append(columns, "slug")
+	}
+	if req.Description != nil {
+		service.Description = *req.Description
+		columns = append(columns, "description")
+	}
+	if req.BasePrice != nil {
+		service.BasePrice = *req.BasePrice
+		columns = append(columns, "base_price")
+	}
+	if req.GSTRate != nil {
+		service.GSTRate = *req.GSTRate
+		columns = append(columns, "gst_rate")
+	}
+	var requirements []models.DocumentRequirement
+	if req.DocumentRequirements != nil {
+		var err error
+		if requirements, err = buildDocumentRequirements(req.DocumentRequirements); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.FormSchema != nil {
+		// An empty object removes the form; existing applications keep their data
+		if req.FormSchema.Type == "" && len(req.FormSchema.Properties) == 0 {
+			service.FormSchema = nil
+		} else if err := req.FormSchema.Check(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else {
+			service.FormSchema = req.FormSchema
+		}
+		columns = append(columns, "form_schema")
+	}
+	if req.SLADays != nil {
+		service.SLADays = *req.SLADays
+		columns = append(columns, "sla_days")
+	}
+	if req.IsActive != nil {
+		service.IsActive = *req.IsActive
+		columns = append(columns, "is_active")
+	}
+	if len(columns) == 0 && req.DocumentRequirements == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+
+	if serviceTaken(service.Name, service.Slug, service.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A service with this name or slug already exists"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(columns) > 0 {
+			if err := tx.Model(&service).Omit(clause.Associations).Select(columns).Updates(&service).Error; err != nil {
+				return err
+			}
+		}
+		if req.DocumentRequirements != nil {
+			return replaceDocumentRequirements(tx, service.ID, requirements)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service"})
+		return
+	}
+
+	database.DB.Preload("DocumentRequirements", orderRequirements).First(&service, id)
+
+	values := serviceFieldValues(&service)
+	if req.DocumentRequirements != nil {
+		columns = append(columns, "document_requirements")
+	}
+	after := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		after[column] = values[column]
+	}
+	middleware.SetAuditChanges(c, before, after)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service updated successfully",
+		"service": service,
+	})
+}
+
+// DeleteService removes a service no application refers to (admin only).
+// Services in use should be deactivated instead.
+func DeleteService(c *gin.Context) {
+	id := c.Param("id")
+
+	var service models.Service
+	if err := database.DB.First(&service, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	var applications int64
+	database.DB.Unscoped().Model(&models.Application{}).Where("service_id = ?", service.ID).Count(&applications)
+	if applications > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Service has applications; deactivate it instead"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_id = ?", service.ID).Delete(&models.DocumentRequirement{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&service).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service deleted successfully"})
+}
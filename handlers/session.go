@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// createSession stores a new refresh token session for the user and
+// returns an access token bound to it along with the refresh token
+func createSession(tx *gorm.DB, c *gin.Context, user models.User) (*models.Session, string, string, error) {
+	refreshToken, err := utils.GenerateRandomToken()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	session := models.Session{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenExpiry()),
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+
+	if err := tx.Create(&session).Error; err != nil {
+		return nil, "", "", err
+	}
+
+	accessToken, err := utils.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return &session, accessToken, refreshToken, nil
+}
+
+// revokeUserSessions revokes every active session of a user, along with the
+// access tokens issued for them
+func revokeUserSessions(userID uint) error {
+	err := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	middleware.InvalidateSessionCache(userID)
+	return err
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a rotated refresh token
+func RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var session models.Session
+	if err := database.DB.Preload("User").Where("token_hash = ?", utils.HashToken(req.RefreshToken)).First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid refresh token",
+		})
+		return
+	}
+
+	// A revoked token being presented again means it was leaked or replayed;
+	// revoke every session of the user so the attacker's copy stops working too
+	if session.RevokedAt != nil {
+		revokeUserSessions(session.UserID)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Refresh token has been revoked",
+		})
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Refresh token has expired",
+		})
+		return
+	}
+
+	if !session.User.IsActive {
+		revokeUserSessions(session.UserID)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Account is deactivated",
+		})
+		return
+	}
+
+	// Rotate: revoke the presented session and issue a new one in a single transaction
+	var accessToken, refreshToken string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		newSession, newAccessToken, newRefreshToken, err := createSession(tx, c, session.User)
+		if err != nil {
+			return err
+		}
+
+		// Guard against two concurrent refreshes of the same token
+		result := tx.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL", session.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": newSession.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		accessToken, refreshToken = newAccessToken, newRefreshToken
+		return nil
+	})
+	// The rotated session's access token stops working too
+	middleware.InvalidateSessionCache(session.UserID)
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Refresh token has been revoked",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"message": "Failed to refresh token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"message":       "Token refreshed successfully",
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// Logout revokes the session belonging to a refresh token
+func Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var session models.Session
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(req.RefreshToken)).First(&session).Error; err == nil {
+		if err := database.DB.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL", session.ID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"message": "Failed to logout",
+			})
+			return
+		}
+		middleware.InvalidateSessionCache(session.UserID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged out successfully",
+	})
+}
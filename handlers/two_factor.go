@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes issued at a time
+const recoveryCodeCount = 10
+
+// totpIssuer returns the issuer name shown in authenticator apps
+func totpIssuer() string {
+	if issuer := config.GetAuthConfig()["totp_issuer"]; issuer != "" {
+		return issuer
+	}
+	return "Bharat Seva Space"
+}
+
+// generateRecoveryCodes replaces a user's recovery codes and returns the new plain codes
+func generateRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		recoveryCode := models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+		}
+		if err := tx.Create(&recoveryCode).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// verifyTOTPCode checks a TOTP code for an enrolled user, rejecting reuse of a code
+func verifyTOTPCode(user *models.User, code string) bool {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok || step <= user.TOTPLastUsedStep {
+		return false
+	}
+
+	// Record the step atomically so a concurrent request can't reuse the same code
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+		Update("totp_last_used_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+func verifySecondFactor(user *models.User, code string) bool {
+	if verifyTOTPCode(user, code) {
+		return true
+	}
+
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashToken(utils.NormalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// SetupTwoFactor starts TOTP enrollment and returns the secret and provisioning URI
+func SetupTwoFactor(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	if currentUser.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", currentUser.ID).Update("totp_pending_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+	middleware.InvalidateUserCache(currentUser.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Scan the provisioning URI with an authenticator app, then confirm with a code",
+		"secret":           secret,
+		"provisioning_uri": utils.TOTPProvisioningURI(totpIssuer(), currentUser.Email, secret),
+	})
+}
+
+// EnableTwoFactor confirms TOTP enrollment with a code and returns recovery codes
+func EnableTwoFactor(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, currentUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPPendingSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor setup first"})
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var recoveryCodes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":        true,
+			"totp_secret":         user.TOTPPendingSecret,
+			"totp_pending_secret": "",
+			"totp_last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err := generateRecoveryCodes(tx, user.ID)
+		recoveryCodes = codes
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	middleware.InvalidateUserCache(user.ID)
+
+	response := gin.H{
+		"message":        "Two-factor authentication enabled. Store the recovery codes somewhere safe; they are only shown once.",
+		"recovery_codes": recoveryCodes,
+	}
+
+	// Enrollment during a mandatory-2FA login completes the login
+	if scope, _ := c.Get("token_scope"); scope == utils.ScopeTwoFactorSetup {
+		_, token, refreshToken, err := createSession(database.DB, c, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		response["token"] = token
+		response["refresh_token"] = refreshToken
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableTwoFactor turns off two-factor authentication after checking the password and a code
+func DisableTwoFactor(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, currentUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if config.GetAuthConfig()["require_admin_2fa"] == "true" && middleware.IsStaff(&user) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is mandatory for staff accounts"})
+		return
+	}
+	if !utils.CheckPassword(req.Password, user.Password) || !verifySecondFactor(&user, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password or code"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_enabled":        false,
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+			"totp_last_used_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	middleware.InvalidateUserCache(user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes after checking a TOTP code
+func RegenerateRecoveryCodes(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, currentUser.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if !user.TOTPEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if !verifyTOTPCode(&user, req.Code) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var recoveryCodes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		codes, err := generateRecoveryCodes(tx, user.ID)
+		recoveryCodes = codes
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes regenerated. Previous codes no longer work.",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// VerifyTwoFactorLogin completes a login by exchanging a pending token and a TOTP or recovery code
+func VerifyTwoFactorLogin(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	claims, err := utils.ValidateToken(req.Token)
+	if err != nil || claims.Scope != utils.ScopeTwoFactorPending {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid or expired login token",
+		})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil || !user.IsActive || !user.TOTPEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid or expired login token",
+		})
+		return
+	}
+
+	// Failed codes count towards the same lockout as failed passwords
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		recordLoginAttempt(c, &user.ID, user.Email, false, loginReasonAccountLocked)
+		respondLocked(c, "Account temporarily locked due to failed login attempts", time.Until(*user.LockedUntil))
+		return
+	}
+
+	if !verifySecondFactor(&user, req.Code) {
+		recordLoginAttempt(c, &user.ID, user.Email, false, loginReasonInvalidTwoFactor)
+		if lockout := registerFailedLogin(&user, getLoginProtectionSettings()); lockout > 0 {
+			respondLocked(c, "Account temporarily locked due to failed login attempts", lockout)
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"message": "Invalid two-factor code",
+		})
+		return
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		resetFailedLogins(user.ID)
+	}
+	recordLoginAttempt(c, &user.ID, user.Email, true, "")
+
+	finishLogin(c, user)
+}
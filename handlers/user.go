@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"bharat-seva-space/database"
+	"bharat-seva-space/middleware"
 	"bharat-seva-space/models"
 
 	"github.com/gin-gonic/gin"
@@ -41,13 +43,19 @@ func GetAllUsers(c *gin.Context) {
 	var responses []models.UserResponse
 	for _, user := range users {
 		response := models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			Phone:     user.Phone,
-			Name:      user.Name,
-			Role:      user.Role,
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
+			ID:                 user.ID,
+			Email:              user.Email,
+			Phone:              user.Phone,
+			Name:               user.Name,
+			Role:               user.Role,
+			IsActive:           user.IsActive,
+			MustChangePassword: user.MustChangePassword,
+			EmailVerified:      user.EmailVerifiedAt != nil,
+			PhoneVerified:      user.PhoneVerifiedAt != nil,
+			TwoFactorEnabled:   user.TOTPEnabled,
+			State:              user.State,
+			GSTIN:              user.GSTIN,
+			CreatedAt:          user.CreatedAt,
 		}
 		responses = append(responses, response)
 	}
@@ -73,33 +81,25 @@ func GetUser(c *gin.Context) {
 	}
 
 	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+		ID:                 user.ID,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		Name:               user.Name,
+		Role:               user.Role,
+		IsActive:           user.IsActive,
+		MustChangePassword: user.MustChangePassword,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		PhoneVerified:      user.PhoneVerifiedAt != nil,
+		TwoFactorEnabled:   user.TOTPEnabled,
+		State:              user.State,
+		GSTIN:              user.GSTIN,
+		CreatedAt:          user.CreatedAt,
 	}
 
 	// Get user's applications
 	var applications []models.ApplicationResponse
 	for _, app := range user.Applications {
-		appResponse := models.ApplicationResponse{
-			ID:            app.ID,
-			UserID:        app.UserID,
-			ServiceType:   app.ServiceType,
-			Status:        app.Status,
-			Progress:      app.Progress,
-			PaymentStatus: app.PaymentStatus,
-			Amount:        app.Amount,
-			Description:   app.Description,
-			AssignedCA:    app.AssignedCA,
-			Notes:         app.Notes,
-			CreatedAt:     app.CreatedAt,
-			UpdatedAt:     app.UpdatedAt,
-		}
-		applications = append(applications, appResponse)
+		applications = append(applications, applicationResponse(app))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -116,7 +116,7 @@ func UpdateUser(c *gin.Context) {
 		Name     string `json:"name"`
 		Phone    string `json:"phone"`
 		Role     string `json:"role"`
-		IsActive bool   `json:"is_active"`
+		IsActive *bool  `json:"is_active"` // Unchanged when omitted
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -131,6 +131,16 @@ func UpdateUser(c *gin.Context) {
 		return
 	}
 
+	previousRole := user.Role
+	wasActive := user.IsActive
+	before := map[string]interface{}{
+		"name":              user.Name,
+		"phone":             user.Phone,
+		"phone_verified_at": user.PhoneVerifiedAt,
+		"role":              user.Role,
+		"is_active":         user.IsActive,
+	}
+
 	// Update user
 	updates := make(map[string]interface{})
 	if req.Name != "" {
@@ -138,28 +148,76 @@ func UpdateUser(c *gin.Context) {
 	}
 	if req.Phone != "" {
 		updates["phone"] = req.Phone
+		// A new phone number has to be verified again
+		if req.Phone != user.Phone {
+			updates["phone_verified_at"] = nil
+		}
 	}
-	if req.Role != "" {
+	if req.Role != "" && req.Role != user.Role {
+		// Changing roles needs roles:manage on top of users:manage
+		userInterface, _ := c.Get("user")
+		if !middleware.HasPermission(userInterface.(*models.User), models.PermissionRolesManage) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Permission %s required", models.PermissionRolesManage)})
+			return
+		}
+		if err := validateRoleChange(&user, req.Role); err != nil {
+			status := http.StatusBadRequest
+			if err == errLastAdmin {
+				status = http.StatusConflict
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
 		updates["role"] = req.Role
 	}
-	updates["is_active"] = req.IsActive
+	deactivated := req.IsActive != nil && !*req.IsActive && wasActive
+	if req.IsActive != nil && *req.IsActive != wasActive {
+		if deactivated {
+			if err := ensureOtherActiveAdmin(&user); err != nil {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		updates["is_active"] = *req.IsActive
+	}
 
-	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
-		return
+	if len(updates) > 0 {
+		if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+	}
+
+	// Make sure the next request sees the new role and activation state
+	middleware.InvalidateUserCache(user.ID)
+	middleware.SetAuditChanges(c, before, updates)
+
+	// Deactivation or a role change invalidates every existing session
+	roleChanged := req.Role != "" && req.Role != previousRole
+	if roleChanged || deactivated {
+		if err := revokeUserSessions(user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user sessions"})
+			return
+		}
 	}
 
 	// Get updated user
 	database.DB.First(&user, id)
 
 	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Phone:     user.Phone,
-		Name:      user.Name,
-		Role:      user.Role,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+		ID:                 user.ID,
+		Email:              user.Email,
+		Phone:              user.Phone,
+		Name:               user.Name,
+		Role:               user.Role,
+		IsActive:           user.IsActive,
+		MustChangePassword: user.MustChangePassword,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		PhoneVerified:      user.PhoneVerifiedAt != nil,
+		TwoFactorEnabled:   user.TOTPEnabled,
+		State:              user.State,
+		GSTIN:              user.GSTIN,
+		CreatedAt:          user.CreatedAt,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -168,14 +226,40 @@ func UpdateUser(c *gin.Context) {
 	})
 }
 
+// ForcePasswordChange requires a user to change their password at next login (admin only)
+func ForcePasswordChange(c *gin.Context) {
+	id := c.Param("id")
+
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := database.DB.Model(&user).Update("must_change_password", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	// Existing sessions must not keep full access
+	middleware.InvalidateUserCache(user.ID)
+	if err := revokeUserSessions(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User must change password at next login"})
+}
+
 // GetUserStats returns user statistics (admin only)
 func GetUserStats(c *gin.Context) {
 	var stats struct {
-		TotalUsers    int64 `json:"total_users"`
-		ActiveUsers   int64 `json:"active_users"`
-		InactiveUsers int64 `json:"inactive_users"`
-		AdminUsers    int64 `json:"admin_users"`
-		RegularUsers  int64 `json:"regular_users"`
+		TotalUsers    int64            `json:"total_users"`
+		ActiveUsers   int64            `json:"active_users"`
+		InactiveUsers int64            `json:"inactive_users"`
+		AdminUsers    int64            `json:"admin_users"`
+		RegularUsers  int64            `json:"regular_users"`
+		ByRole        map[string]int64 `json:"by_role"`
 	}
 
 	database.DB.Model(&models.User{}).Count(&stats.TotalUsers)
@@ -184,6 +268,16 @@ func GetUserStats(c *gin.Context) {
 	database.DB.Model(&models.User{}).Where("role = ?", "admin").Count(&stats.AdminUsers)
 	database.DB.Model(&models.User{}).Where("role = ?", "user").Count(&stats.RegularUsers)
 
+	var roleCounts []struct {
+		Role  string
+		Count int64
+	}
+	database.DB.Model(&models.User{}).Select("role, count(*) as count").Group("role").Scan(&roleCounts)
+	stats.ByRole = make(map[string]int64, len(roleCounts))
+	for _, rc := range roleCounts {
+		stats.ByRole[rc.Role] = rc.Count
+	}
+
 	c.JSON(http.StatusOK, gin.H{"stats": stats})
 }
 
@@ -204,7 +298,7 @@ func GetDashboardData(c *gin.Context) {
 	query := database.DB.Model(&models.Application{})
 	
 	// If user is not admin, only show their applications
-	if currentUser.Role != "admin" {
+	if !middleware.HasPermission(currentUser, models.PermissionApplicationsRead) {
 		query = query.Where("user_id = ?", currentUser.ID)
 	}
 
@@ -217,46 +311,19 @@ func GetDashboardData(c *gin.Context) {
 	// Get recent applications
 	var recentApplications []models.Application
 	recentQuery := database.DB.Preload("AssignedCAUser")
-	if currentUser.Role != "admin" {
+	if !middleware.HasPermission(currentUser, models.PermissionApplicationsRead) {
 		recentQuery = recentQuery.Where("user_id = ?", currentUser.ID)
 	}
 	recentQuery.Order("created_at DESC").Limit(5).Find(&recentApplications)
 
 	var recentResponses []models.ApplicationResponse
 	for _, app := range recentApplications {
-		response := models.ApplicationResponse{
-			ID:            app.ID,
-			UserID:        app.UserID,
-			ServiceType:   app.ServiceType,
-			Status:        app.Status,
-			Progress:      app.Progress,
-			PaymentStatus: app.PaymentStatus,
-			Amount:        app.Amount,
-			Description:   app.Description,
-			AssignedCA:    app.AssignedCA,
-			Notes:         app.Notes,
-			CreatedAt:     app.CreatedAt,
-			UpdatedAt:     app.UpdatedAt,
-		}
-
-		if app.AssignedCAUser != nil {
-			response.AssignedCAUser = &models.UserResponse{
-				ID:        app.AssignedCAUser.ID,
-				Email:     app.AssignedCAUser.Email,
-				Phone:     app.AssignedCAUser.Phone,
-				Name:      app.AssignedCAUser.Name,
-				Role:      app.AssignedCAUser.Role,
-				IsActive:  app.AssignedCAUser.IsActive,
-				CreatedAt: app.AssignedCAUser.CreatedAt,
-			}
-		}
-
-		recentResponses = append(recentResponses, response)
+		recentResponses = append(recentResponses, applicationResponse(app))
 	}
 
-	// If admin, get additional stats
+	// Staff who handle queries get additional stats
 	var adminStats *gin.H
-	if currentUser.Role == "admin" {
+	if middleware.HasPermission(currentUser, models.PermissionQueriesRead) {
 		var queryStats struct {
 			Total      int64 `json:"total"`
 			New        int64 `json:"new"`
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/mailer"
+	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
+	"bharat-seva-space/sms"
+	"bharat-seva-space/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// otpDigits is the length of generated one-time passwords
+const otpDigits = 6
+
+var (
+	errOTPInvalid         = errors.New("invalid or expired code")
+	errOTPTooManyAttempts = errors.New("too many incorrect attempts, request a new code")
+	errOTPResendTooSoon   = errors.New("a code was sent recently, please wait before requesting another")
+)
+
+// otpMessages holds the text sent for each OTP purpose
+var otpMessages = map[string]string{
+	models.OTPPurposeVerifyEmail: "Your Bharat Seva Space email verification code is %s. It expires in %s.",
+	models.OTPPurposeVerifyPhone: "Your Bharat Seva Space phone verification code is %s. It expires in %s.",
+}
+
+// otpSettings returns the OTP expiry, maximum verification attempts and resend interval
+func otpSettings() (time.Duration, int, time.Duration) {
+	verificationConfig := config.GetVerificationConfig()
+
+	expiry, err := time.ParseDuration(verificationConfig["otp_expiry"])
+	if err != nil {
+		expiry = 10 * time.Minute // default to 10 minutes
+	}
+	maxAttempts, err := strconv.Atoi(verificationConfig["otp_max_attempts"])
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	resendInterval, err := time.ParseDuration(verificationConfig["otp_resend_interval"])
+	if err != nil {
+		resendInterval = time.Minute // default to 1 minute
+	}
+
+	return expiry, maxAttempts, resendInterval
+}
+
+// issueOTP creates a new OTP for a purpose and destination, replacing any
+// outstanding one, and delivers it by email or SMS
+func issueOTP(ctx context.Context, userID *uint, purpose, channel, destination string) error {
+	expiry, _, resendInterval := otpSettings()
+
+	var recent int64
+	database.DB.Model(&models.OTP{}).
+		Where("purpose = ? AND destination = ? AND created_at > ?", purpose, destination, time.Now().Add(-resendInterval)).
+		Count(&recent)
+	if recent > 0 {
+		return errOTPResendTooSoon
+	}
+
+	code, err := utils.GenerateOTP(otpDigits)
+	if err != nil {
+		return err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("purpose = ? AND destination = ? AND consumed_at IS NULL", purpose, destination).Delete(&models.OTP{}).Error; err != nil {
+			return err
+		}
+
+		otp := models.OTP{
+			UserID:      userID,
+			Purpose:     purpose,
+			Destination: destination,
+			CodeHash:    utils.HashOTP(purpose, destination, code),
+			ExpiresAt:   time.Now().Add(expiry),
+		}
+		return tx.Create(&otp).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(otpMessages[purpose], code, expiry)
+	if channel == "email" {
+		return mailer.Default.Send(ctx, mailer.Message{
+			To:      destination,
+			Subject: "Your Bharat Seva Space verification code",
+			Body:    text,
+		})
+	}
+	return sms.Default.Send(ctx, destination, text)
+}
+
+// verifyOTP checks a code against the outstanding OTP for a purpose and
+// destination, consuming it on success and counting failed attempts
+func verifyOTP(purpose, destination, code string) (*models.OTP, error) {
+	_, maxAttempts, _ := otpSettings()
+
+	var otp models.OTP
+	if err := database.DB.Where("purpose = ? AND destination = ? AND consumed_at IS NULL", purpose, destination).
+		Order("created_at DESC").First(&otp).Error; err != nil {
+		return nil, errOTPInvalid
+	}
+
+	if time.Now().After(otp.ExpiresAt) {
+		return nil, errOTPInvalid
+	}
+	if otp.Attempts >= maxAttempts {
+		return nil, errOTPTooManyAttempts
+	}
+
+	if !utils.CheckOTP(purpose, destination, code, otp.CodeHash) {
+		database.DB.Model(&otp).Update("attempts", gorm.Expr("attempts + 1"))
+		if otp.Attempts+1 >= maxAttempts {
+			return nil, errOTPTooManyAttempts
+		}
+		return nil, errOTPInvalid
+	}
+
+	// Consume atomically so the same code can't be used twice
+	result := database.DB.Model(&models.OTP{}).
+		Where("id = ? AND consumed_at IS NULL", otp.ID).
+		Update("consumed_at", time.Now())
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errOTPInvalid
+	}
+
+	return &otp, nil
+}
+
+// verificationTarget returns the OTP purpose and destination for a channel
+func verificationTarget(user *models.User, channel string) (string, string) {
+	if channel == "email" {
+		return models.OTPPurposeVerifyEmail, user.Email
+	}
+	return models.OTPPurposeVerifyPhone, user.Phone
+}
+
+// SendVerificationCode sends an email or phone verification code to the current user
+func SendVerificationCode(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.VerificationSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if (req.Channel == "email" && currentUser.EmailVerifiedAt != nil) || (req.Channel == "phone" && currentUser.PhoneVerifiedAt != nil) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Your %s is already verified", req.Channel)})
+		return
+	}
+
+	purpose, destination := verificationTarget(currentUser, req.Channel)
+	if err := issueOTP(c.Request.Context(), &currentUser.ID, purpose, req.Channel, destination); err != nil {
+		if err == errOTPResendTooSoon {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification code"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent"})
+}
+
+// VerifyContact verifies the current user's email or phone with a code
+func VerifyContact(c *gin.Context) {
+	userInterface, _ := c.Get("user")
+	currentUser := userInterface.(*models.User)
+
+	var req models.VerificationVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	purpose, destination := verificationTarget(currentUser, req.Channel)
+	if _, err := verifyOTP(purpose, destination, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	column := "email_verified_at"
+	if req.Channel == "phone" {
+		column = "phone_verified_at"
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", currentUser.ID).Update(column, time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update verification status"})
+		return
+	}
+	middleware.InvalidateUserCache(currentUser.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Your %s has been verified", req.Channel)})
+}
@@ -0,0 +1,256 @@
+package invoice
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"bharat-seva-space/models"
+)
+
+// IST is the time zone invoice dates and financial years are reckoned in
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// FinancialYear returns the Indian financial year (April to March) containing t, e.g. "2026-27"
+func FinancialYear(t time.Time) string {
+	t = t.In(IST)
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// Number formats an invoice number such as "INV/26-27/00001". GST invoice numbers
+// may be at most 16 characters, so the prefix should be short.
+func Number(prefix, financialYear string, sequence int) string {
+	return fmt.Sprintf("%s/%s/%05d", prefix, financialYear[2:], sequence)
+}
+
+// SplitGST splits a total that includes GST at rate percent into the taxable value
+// and the tax. Intra-state supplies are taxed half as CGST and half as SGST,
+// inter-state supplies as IGST. The parts always add up to the total.
+func SplitGST(total, rate float64, interState bool) (taxable, cgst, sgst, igst float64) {
+	totalPaise := int64(math.Round(total * 100))
+	taxablePaise := int64(math.Round(float64(totalPaise) * 100 / (100 + rate)))
+	taxPaise := totalPaise - taxablePaise
+
+	taxable = float64(taxablePaise) / 100
+	if interState {
+		return taxable, 0, 0, float64(taxPaise) / 100
+	}
+	cgstPaise := (taxPaise + 1) / 2
+	return taxable, float64(cgstPaise) / 100, float64(taxPaise-cgstPaise) / 100, 0
+}
+
+// StateName returns the name of a GST state code, followed by the code
+func StateName(code string) string {
+	if name, ok := models.GSTStates[code]; ok {
+		return fmt.Sprintf("%s (%s)", name, code)
+	}
+	return code
+}
+
+// Layout of the invoice page
+const (
+	marginLeft  = 50.0
+	marginRight = pageWidth - 50.0
+	bodySize    = 10.0
+	smallSize   = 8.5
+	lineHeight  = 14.0
+)
+
+// Render draws a tax invoice as a PDF
+func Render(inv *models.Invoice) []byte {
+	d := &pdfDocument{}
+	d.addPage()
+
+	d.text(marginLeft, 70, 18, true, "TAX INVOICE")
+	d.textRight(marginRight, 70, bodySize, false, "Original for Recipient")
+	d.line(marginLeft, 82, marginRight, 82, 1)
+
+	// Seller on the left, invoice details on the right
+	y := 104.0
+	d.text(marginLeft, y, 12, true, inv.SellerName)
+	y += lineHeight
+	for _, line := range wrapText(inv.SellerAddress, 260, bodySize, false) {
+		if line != "" {
+			d.text(marginLeft, y, bodySize, false, line)
+			y += lineHeight
+		}
+	}
+	d.text(marginLeft, y, bodySize, false, "GSTIN: "+inv.SellerGSTIN)
+	y += lineHeight
+	d.text(marginLeft, y, bodySize, false, "State: "+StateName(inv.SellerState))
+	sellerBottom := y
+
+	details := [][2]string{
+		{"Invoice No.", inv.Number},
+		{"Invoice Date", inv.IssuedAt.In(IST).Format("02 Jan 2006")},
+		{"Application", "#" + strconv.FormatUint(uint64(inv.ApplicationID), 10)},
+		{"Place of Supply", StateName(inv.PlaceOfSupply)},
+		{"Reverse Charge", "No"},
+	}
+	y = 104.0
+	for _, detail := range details {
+		d.text(330, y, bodySize, true, detail[0])
+		for _, line := range wrapText(detail[1], marginRight-420, bodySize, false) {
+			d.text(420, y, bodySize, false, line)
+			y += lineHeight
+		}
+	}
+
+	// Customer
+	y = math.Max(sellerBottom, y) + 16
+	d.line(marginLeft, y-10, marginRight, y-10, 0.5)
+	y += 6
+	d.text(marginLeft, y, bodySize, true, "Bill To")
+	y += lineHeight
+	for _, line := range wrapText(inv.CustomerName, 260, bodySize, false) {
+		d.text(marginLeft, y, bodySize, false, line)
+		y += lineHeight
+	}
+	if inv.CustomerEmail != "" {
+		d.text(marginLeft, y, bodySize, false, inv.CustomerEmail)
+		y += lineHeight
+	}
+	if inv.CustomerGSTIN != "" {
+		d.text(marginLeft, y, bodySize, false, "GSTIN: "+inv.CustomerGSTIN)
+		y += lineHeight
+	}
+	d.text(marginLeft, y, bodySize, false, "State: "+StateName(inv.PlaceOfSupply))
+	y += lineHeight + 16
+
+	// Line item
+	columnSAC, columnRate := 330.0, 400.0
+	d.line(marginLeft, y-12, marginRight, y-12, 0.5)
+	d.text(marginLeft, y, bodySize, true, "Description")
+	d.text(columnSAC, y, bodySize, true, "SAC")
+	d.text(columnRate, y, bodySize, true, "GST Rate")
+	d.textRight(marginRight, y, bodySize, true, "Taxable Value")
+	d.line(marginLeft, y+6, marginRight, y+6, 0.5)
+	y += lineHeight + 8
+	descriptionLines := wrapText(inv.Description, columnSAC-marginLeft-10, bodySize, false)
+	d.text(columnSAC, y, bodySize, false, inv.SAC)
+	d.text(columnRate, y, bodySize, false, formatRate(inv.GSTRate))
+	d.textRight(marginRight, y, bodySize, false, formatRupees(inv.TaxableAmount))
+	for _, line := range descriptionLines {
+		d.text(marginLeft, y, bodySize, false, line)
+		y += lineHeight
+	}
+	d.line(marginLeft, y-6, marginRight, y-6, 0.5)
+	y += 12
+
+	// Totals
+	totals := [][2]string{{"Taxable Value", formatRupees(inv.TaxableAmount)}}
+	if inv.SupplyType == models.SupplyInterState {
+		totals = append(totals, [2]string{"IGST @ " + formatRate(inv.GSTRate), formatRupees(inv.IGST)})
+	} else {
+		half := formatRate(inv.GSTRate / 2)
+		totals = append(totals,
+			[2]string{"CGST @ " + half, formatRupees(inv.CGST)},
+			[2]string{"SGST @ " + half, formatRupees(inv.SGST)})
+	}
+	for _, total := range totals {
+		d.text(340, y, bodySize, false, total[0])
+		d.textRight(marginRight, y, bodySize, false, total[1])
+		y += lineHeight
+	}
+	d.line(340, y-8, marginRight, y-8, 0.5)
+	y += 6
+	d.text(340, y, 11, true, "Total (INR)")
+	d.textRight(marginRight, y, 11, true, formatRupees(inv.Total))
+	y += lineHeight + 16
+
+	d.text(marginLeft, y, bodySize, true, "Amount in words")
+	y += lineHeight
+	for _, line := range wrapText(amountInWords(inv.Total), marginRight-marginLeft, bodySize, false) {
+		d.text(marginLeft, y, bodySize, false, line)
+		y += lineHeight
+	}
+
+	d.line(marginLeft, pageHeight-70, marginRight, pageHeight-70, 0.5)
+	d.text(marginLeft, pageHeight-56, smallSize, false, "This is a computer generated invoice and does not require a signature.")
+
+	return d.bytes()
+}
+
+// formatRate formats a GST percentage without trailing zeros, e.g. "18%" or "2.5%"
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64) + "%"
+}
+
+// formatRupees formats an amount with Indian digit grouping, e.g. "1,23,456.00"
+func formatRupees(amount float64) string {
+	paise := int64(math.Round(amount * 100))
+	sign := ""
+	if paise < 0 {
+		sign = "-"
+		paise = -paise
+	}
+	digits := strconv.FormatInt(paise/100, 10)
+
+	// The last three digits form a group, then every two digits before them
+	grouped := digits
+	if len(digits) > 3 {
+		head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+		var groups []string
+		for len(head) > 2 {
+			groups = append([]string{head[len(head)-2:]}, groups...)
+			head = head[:len(head)-2]
+		}
+		groups = append([]string{head}, groups...)
+		grouped = strings.Join(groups, ",") + "," + tail
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, grouped, paise%100)
+}
+
+var (
+	ones = []string{"", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine",
+		"Ten", "Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen", "Seventeen", "Eighteen", "Nineteen"}
+	tens = []string{"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety"}
+)
+
+// amountInWords spells out an amount in the Indian numbering system, e.g.
+// "Rupees One Lakh Twenty Thousand and Fifty Paise Only"
+func amountInWords(amount float64) string {
+	paise := int64(math.Round(amount * 100))
+	rupees, paise := paise/100, paise%100
+
+	words := "Rupees " + numberInWords(rupees)
+	if paise > 0 {
+		words += " and " + numberInWords(paise) + " Paise"
+	}
+	return words + " Only"
+}
+
+// numberInWords spells out a whole number using crore, lakh and thousand
+func numberInWords(n int64) string {
+	if n == 0 {
+		return "Zero"
+	}
+
+	var parts []string
+	for _, unit := range []struct {
+		value int64
+		name  string
+	}{{10000000, "Crore"}, {100000, "Lakh"}, {1000, "Thousand"}, {100, "Hundred"}} {
+		if n >= unit.value {
+			count := n / unit.value
+			n %= unit.value
+			parts = append(parts, numberInWords(count)+" "+unit.name)
+		}
+	}
+	if n > 0 {
+		if n < 20 {
+			parts = append(parts, ones[n])
+		} else if n%10 == 0 {
+			parts = append(parts, tens[n/10])
+		} else {
+			parts = append(parts, tens[n/10]+" "+ones[n%10])
+		}
+	}
+	return strings.Join(parts, " ")
+}
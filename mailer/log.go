@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to the application log, or appends them to a
+// file when a path is configured. Intended for local development and testing.
+type LogMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogMailer creates a mailer that logs messages, or writes them to path if set
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{path: path}
+}
+
+// Send records the message instead of delivering it
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	entry := fmt.Sprintf("Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n---\n",
+		time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+
+	if m.path == "" {
+		log.Printf("Mail (not sent):\n%s", entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %v", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"bharat-seva-space/config"
+)
+
+// Message is an outgoing plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Default is the mailer used by the application
+var Default Mailer
+
+// InitMailer initializes the mailer selected by MAIL_BACKEND
+func InitMailer() error {
+	mailConfig := config.GetMailConfig()
+
+	switch mailConfig["backend"] {
+	case "", "log":
+		Default = NewLogMailer(mailConfig["log_path"])
+	case "smtp":
+		smtpMailer, err := NewSMTPMailer(SMTPConfig{
+			Host:     mailConfig["smtp_host"],
+			Port:     mailConfig["smtp_port"],
+			Username: mailConfig["smtp_username"],
+			Password: mailConfig["smtp_password"],
+			From:     mailConfig["from"],
+		})
+		if err != nil {
+			return err
+		}
+		Default = smtpMailer
+	default:
+		return fmt.Errorf("unknown mail backend: %s", mailConfig["backend"])
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the settings for an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer delivers messages through an SMTP server.
+// STARTTLS is used automatically when the server supports it.
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer creates an SMTP mailer
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("SMTP_HOST is required for the smtp mail backend")
+	}
+	if cfg.From == "" {
+		return nil, errors.New("MAIL_FROM is required for the smtp mail backend")
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &SMTPMailer{cfg: cfg}, nil
+}
+
+// Send delivers the message
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	// Reject header injection through recipient or subject
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return errors.New("invalid characters in mail header")
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + m.cfg.From,
+		"To: " + msg.To,
+		"Subject: " + msg.Subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Body,
+	}, "\r\n")
+
+	// MAIL_FROM may include a display name; the envelope needs the bare address
+	envelopeFrom := m.cfg.From
+	if addr, err := mail.ParseAddress(m.cfg.From); err == nil {
+		envelopeFrom = addr.Address
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+	if err := smtp.SendMail(addr, auth, envelopeFrom, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	return nil
+}
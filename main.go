@@ -1,41 +1,67 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 
 	"bharat-seva-space/config"
 	"bharat-seva-space/database"
-	"bharat-seva-space/routes"
 )
 
+const usage = `Usage: bharat-seva <command> [arguments]
+
+Commands:
+  serve                      Start the API server (default when no command is given)
+  migrate up|down|status     Apply, roll back or list schema migrations
+  seed                       Insert demo users, queries and applications
+  admin create               Create an admin account
+  user list|set-role|activate|deactivate|unlock
+                             Manage user accounts
+  export users|queries|applications|audit
+                             Export data as CSV or JSON
+  fake-gateway               Run a local fake payment gateway for development
+
+Run "bharat-seva <command> -h" for the options of a command.
+`
+
 func main() {
 	// Load environment variables
 	if err := config.LoadEnv(); err != nil {
 		log.Fatal("Error loading environment variables:", err)
 	}
 
-	// Initialize database
-	if err := database.InitDB(); err != nil {
-		log.Fatal("Error initializing database:", err)
-	}
-
-	// Create default admin user
-	if err := database.CreateAdminUser(); err != nil {
-		log.Fatal("Error creating admin user:", err)
+	command, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
 	}
 
-	// Setup routes
-	router := routes.SetupRoutes()
-
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	switch command {
+	case "serve":
+		runServe(args)
+	case "migrate":
+		runMigrate(args)
+	case "seed":
+		runSeed(args)
+	case "admin":
+		runAdmin(args)
+	case "user":
+		runUser(args)
+	case "export":
+		runExport(args)
+	case "fake-gateway":
+		runFakeGateway(args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
 	}
+}
 
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Error starting server:", err)
+// connectDB initializes the database connection for a command, exiting on failure
+func connectDB() {
+	if err := database.InitDB(); err != nil {
+		log.Fatal("Error initializing database:", err)
 	}
-} 
\ No newline at end of file
+}
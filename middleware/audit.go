@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditChangesKey is the context key handlers use to report a field diff
+const auditChangesKey = "audit_changes"
+
+// maxAuditBodySize caps how much of a request body is kept in the audit log
+const maxAuditBodySize = 64 << 10
+
+// auditRedactedKeys are request fields whose values are never stored
+var auditRedactedKeys = []string{"password", "secret", "token", "code"}
+
+// SetAuditChanges reports the fields an admin request changed. Only fields whose
+// value differs between before and after are recorded.
+func SetAuditChanges(c *gin.Context, before, after map[string]interface{}) {
+	changes := make(map[string]models.AuditChange)
+	for field, newValue := range after {
+		oldValue := before[field]
+		// Compare the JSON form so pointers and their values compare equal
+		oldJSON, _ := json.Marshal(oldValue)
+		newJSON, _ := json.Marshal(newValue)
+		if bytes.Equal(oldJSON, newJSON) {
+			continue
+		}
+		changes[field] = models.AuditChange{Old: oldValue, New: newValue}
+	}
+	c.Set(auditChangesKey, changes)
+}
+
+// AuditLog records every mutating request in the group with its actor, target and outcome.
+// It must run after AuthMiddleware.
+func AuditLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		request := readAuditBody(c)
+
+		c.Next()
+
+		entry := models.AuditLog{
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Path:       c.Request.URL.Path,
+			EntityID:   c.Param("id"),
+			Request:    request,
+			StatusCode: c.Writer.Status(),
+			IPAddress:  c.ClientIP(),
+		}
+		if userInterface, ok := c.Get("user"); ok {
+			user := userInterface.(*models.User)
+			entry.ActorID = user.ID
+			entry.ActorEmail = user.Email
+		}
+
+		// The entity is the first segment after the group prefix, e.g. /api/admin/users/:id -> users
+		if rest := strings.TrimPrefix(entry.Route, "/api/admin/"); rest != entry.Route {
+			entry.EntityType = strings.SplitN(rest, "/", 2)[0]
+		}
+
+		if changes, ok := c.Get(auditChangesKey); ok {
+			entry.Changes = changes.(map[string]models.AuditChange)
+		}
+
+		if err := database.DB.Create(&entry).Error; err != nil {
+			log.Printf("Failed to write audit log for %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+}
+
+// readAuditBody returns a JSON request body with secrets redacted and puts the
+// body back for the handler. Other content types are not recorded.
+func readAuditBody(c *gin.Context) string {
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return ""
+	}
+
+	head, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBodySize+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+	if err != nil || len(head) > maxAuditBodySize {
+		return ""
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(head, &body); err != nil {
+		return ""
+	}
+	redacted, _ := json.Marshal(redactAuditValue(body))
+	return string(redacted)
+}
+
+// redactAuditValue replaces the values of sensitive keys anywhere in a decoded JSON value
+func redactAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if isRedactedAuditKey(key) {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redactAuditValue(inner)
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactAuditValue(inner)
+		}
+	}
+	return value
+}
+
+// isRedactedAuditKey reports whether a field name looks like it holds a secret
+func isRedactedAuditKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, redacted := range auditRedactedKeys {
+		if strings.Contains(key, redacted) {
+			return true
+		}
+	}
+	return false
+}
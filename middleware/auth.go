@@ -4,12 +4,36 @@ import (
 	"net/http"
 	"strings"
 
+	"bharat-seva-space/config"
 	"bharat-seva-space/models"
 	"bharat-seva-space/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ChangePasswordPath is the only route reachable while a password change is required
+const ChangePasswordPath = "/api/user/password"
+
+// restrictedScopePaths lists the only routes reachable with each restricted token scope.
+// Scopes not listed here, such as a pending two-factor login, grant no access at all.
+var restrictedScopePaths = map[string][]string{
+	utils.ScopePasswordChange: {ChangePasswordPath},
+	utils.ScopeTwoFactorSetup: {"/api/user/2fa/setup", "/api/user/2fa/enable"},
+}
+
+// scopeAllows reports whether a token with the given scope may access a route
+func scopeAllows(scope, path string) bool {
+	if scope == "" {
+		return true
+	}
+	for _, allowed := range restrictedScopePaths[scope] {
+		if allowed == path {
+			return true
+		}
+	}
+	return false
+}
+
 // AuthMiddleware checks if the user is authenticated
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -29,20 +53,58 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
-		user, err := utils.ExtractUserFromToken(token)
+		claims, err := utils.ValidateToken(token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Add user to context
+		// Full access tokens stop working once their session is revoked by logout,
+		// deactivation or a role change; restricted tokens have no session
+		if claims.Scope == "" && !sessionActive(claims.SessionID, claims.UserID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// Load the current user record so role and activation changes apply immediately
+		user, err := loadUser(claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		if !user.IsActive {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is deactivated"})
+			c.Abort()
+			return
+		}
+
+		// Restricted tokens may only call the endpoints of their scope
+		if !scopeAllows(claims.Scope, c.FullPath()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token is not valid for this endpoint"})
+			c.Abort()
+			return
+		}
+
+		// Users who must change their password may only call the change-password endpoint
+		if user.MustChangePassword && c.FullPath() != ChangePasswordPath {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password change required"})
+			c.Abort()
+			return
+		}
+
+		// Add user and token scope to context
 		c.Set("user", user)
+		c.Set("token_scope", claims.Scope)
 		c.Next()
 	}
 }
 
-// AdminMiddleware checks if the user is an admin
+// AdminMiddleware checks if the user is staff, i.e. their role grants at least one
+// permission. Individual admin routes check specific permissions with RequirePermission.
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userInterface, exists := c.Get("user")
@@ -53,12 +115,19 @@ func AdminMiddleware() gin.HandlerFunc {
 		}
 
 		user := userInterface.(*models.User)
-		if user.Role != "admin" {
+		if !IsStaff(user) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
 			c.Abort()
 			return
 		}
 
+		// Optionally require staff to have two-factor authentication enabled
+		if config.GetAuthConfig()["require_admin_2fa"] == "true" && !user.TOTPEnabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for admin access"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -79,9 +148,11 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
-		user, err := utils.ExtractUserFromToken(token)
-		if err == nil {
-			c.Set("user", user)
+		claims, err := utils.ValidateToken(token)
+		if err == nil && claims.Scope == "" && sessionActive(claims.SessionID, claims.UserID) {
+			if user, err := loadUser(claims.UserID); err == nil && user.IsActive && !user.MustChangePassword {
+				c.Set("user", user)
+			}
 		}
 
 		c.Next()
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedRole is a role record together with the time it stops being valid
+type cachedRole struct {
+	role      models.Role
+	expiresAt time.Time
+}
+
+// roleCache keeps roles in memory so permission checks don't hit the database.
+// It shares USER_CACHE_TTL with the user cache.
+var roleCache = struct {
+	sync.RWMutex
+	entries map[string]cachedRole
+}{entries: make(map[string]cachedRole)}
+
+// loadRole returns a role by name, using the cache when possible
+func loadRole(name string) (*models.Role, error) {
+	now := time.Now()
+
+	roleCache.RLock()
+	entry, ok := roleCache.entries[name]
+	roleCache.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		role := entry.role
+		return &role, nil
+	}
+
+	var role models.Role
+	if err := database.DB.Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+
+	if ttl := userCacheTTL(); ttl > 0 {
+		roleCache.Lock()
+		roleCache.entries[name] = cachedRole{role: role, expiresAt: now.Add(ttl)}
+		roleCache.Unlock()
+	}
+
+	return &role, nil
+}
+
+// InvalidateRoleCache drops all cached roles. Call this whenever a role changes.
+func InvalidateRoleCache() {
+	roleCache.Lock()
+	roleCache.entries = make(map[string]cachedRole)
+	roleCache.Unlock()
+}
+
+// UserPermissions returns the permissions granted by a user's role
+func UserPermissions(user *models.User) []string {
+	role, err := loadRole(user.Role)
+	if err != nil {
+		return []string{}
+	}
+	return role.Permissions
+}
+
+// HasPermission reports whether a user's role grants a permission
+func HasPermission(user *models.User, permission string) bool {
+	role, err := loadRole(user.Role)
+	if err != nil {
+		return false
+	}
+	return role.HasPermission(permission)
+}
+
+// IsStaff reports whether a user's role grants any permission, i.e. access to the admin API
+func IsStaff(user *models.User) bool {
+	return len(UserPermissions(user)) > 0
+}
+
+// RequirePermission only lets users whose role grants the permission through
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userInterface, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			c.Abort()
+			return
+		}
+
+		if !HasPermission(userInterface.(*models.User), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Permission %s required", permission)})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
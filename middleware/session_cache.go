@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+)
+
+// cachedSession records whether a session was active when it was last loaded
+type cachedSession struct {
+	userID    uint
+	active    bool
+	expiresAt time.Time
+}
+
+// sessionCache keeps the state of recently used sessions in memory so that
+// authenticated requests don't query the sessions table every time
+var sessionCache = struct {
+	sync.RWMutex
+	entries map[uint]cachedSession
+}{entries: make(map[uint]cachedSession)}
+
+// sessionActive reports whether the session an access token was issued for
+// belongs to the user and hasn't been revoked, using the cache when possible.
+// Entries live for USER_CACHE_TTL, like cached users.
+func sessionActive(sessionID, userID uint) bool {
+	if sessionID == 0 {
+		return false
+	}
+	now := time.Now()
+
+	sessionCache.RLock()
+	entry, ok := sessionCache.entries[sessionID]
+	sessionCache.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.active && entry.userID == userID
+	}
+
+	var session models.Session
+	if err := database.DB.Select("id", "user_id", "revoked_at").First(&session, sessionID).Error; err != nil {
+		return false
+	}
+	active := session.RevokedAt == nil
+
+	if ttl := userCacheTTL(); ttl > 0 {
+		sessionCache.Lock()
+		// Sweep expired entries occasionally so the cache can't grow without bound
+		if len(sessionCache.entries) >= userCacheSweepSize {
+			for id, e := range sessionCache.entries {
+				if now.After(e.expiresAt) {
+					delete(sessionCache.entries, id)
+				}
+			}
+		}
+		sessionCache.entries[sessionID] = cachedSession{userID: session.UserID, active: active, expiresAt: now.Add(ttl)}
+		sessionCache.Unlock()
+	}
+
+	return active && session.UserID == userID
+}
+
+// InvalidateSessionCache drops a user's sessions from the cache so the next
+// request reloads them. Call this whenever sessions are revoked.
+func InvalidateSessionCache(userID uint) {
+	sessionCache.Lock()
+	for id, e := range sessionCache.entries {
+		if e.userID == userID {
+			delete(sessionCache.entries, id)
+		}
+	}
+	sessionCache.Unlock()
+}
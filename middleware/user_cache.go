@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+)
+
+// cachedUser is a user record together with the time it stops being valid
+type cachedUser struct {
+	user      models.User
+	expiresAt time.Time
+}
+
+// userCacheSweepSize is the number of entries above which expired entries are removed
+const userCacheSweepSize = 1000
+
+// userCache keeps recently loaded users in memory so that authenticated
+// requests don't hit the database every time
+var userCache = struct {
+	sync.RWMutex
+	entries map[uint]cachedUser
+}{entries: make(map[uint]cachedUser)}
+
+// userCacheTTL returns how long a loaded user may be served from the cache
+func userCacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(config.GetAuthConfig()["user_cache_ttl"])
+	if err != nil {
+		ttl = 30 * time.Second // default to 30 seconds
+	}
+	return ttl
+}
+
+// loadUser returns the current user record, using the cache when possible
+func loadUser(userID uint) (*models.User, error) {
+	now := time.Now()
+
+	userCache.RLock()
+	entry, ok := userCache.entries[userID]
+	userCache.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		user := entry.user
+		return &user, nil
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		InvalidateUserCache(userID)
+		return nil, err
+	}
+
+	if ttl := userCacheTTL(); ttl > 0 {
+		userCache.Lock()
+		// Sweep expired entries occasionally so the cache can't grow without bound
+		if len(userCache.entries) >= userCacheSweepSize {
+			for id, e := range userCache.entries {
+				if now.After(e.expiresAt) {
+					delete(userCache.entries, id)
+				}
+			}
+		}
+		userCache.entries[userID] = cachedUser{user: user, expiresAt: now.Add(ttl)}
+		userCache.Unlock()
+	}
+
+	return &user, nil
+}
+
+// InvalidateUserCache drops a user from the cache so the next request reloads it.
+// Call this whenever a user's role, activation or profile changes.
+func InvalidateUserCache(userID uint) {
+	userCache.Lock()
+	delete(userCache.entries, userID)
+	userCache.Unlock()
+}
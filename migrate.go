@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"bharat-seva-space/database"
+)
+
+// runMigrate handles the `migrate` subcommand
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: bharat-seva migrate up|down [--steps N]|status")
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "up":
+		connectDB()
+		if err := database.Migrate(); err != nil {
+			log.Fatal("Error migrating database:", err)
+		}
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		flags.Parse(args[1:])
+
+		connectDB()
+		if err := database.Rollback(*steps); err != nil {
+			log.Fatal("Error rolling back database:", err)
+		}
+	case "status":
+		connectDB()
+		statuses, err := database.GetMigrationStatus()
+		if err != nil {
+			log.Fatal("Error reading migration status:", err)
+		}
+		pending := 0
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			} else {
+				pending++
+			}
+			fmt.Printf("%04d  %-32s %s\n", status.Version, status.Name, state)
+		}
+		if pending > 0 {
+			fmt.Printf("\n%d pending migration(s), run `bharat-seva migrate up`\n", pending)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown migrate command %q\n", args[0])
+		os.Exit(2)
+	}
+}
@@ -8,31 +8,36 @@ import (
 
 // Application represents a service application from registered users
 type Application struct {
-	ID            uint           `json:"id" gorm:"primaryKey"`
-	UserID        uint           `json:"user_id" gorm:"not null"`
-	ServiceType   string         `json:"service_type" gorm:"not null"`
-	Status        string         `json:"status" gorm:"default:'pending'"` // pending, in_progress, completed, cancelled
-	Progress      string         `json:"progress" gorm:"default:'0%'"`
-	PaymentStatus string         `json:"payment_status" gorm:"default:'pending'"` // pending, paid, refunded
-	Amount        float64        `json:"amount" gorm:"default:0"`
-	Description   string         `json:"description" gorm:"type:text"`
-	AssignedCA    *uint          `json:"assigned_ca"` // Admin user ID assigned as CA
-	Notes         string         `json:"notes" gorm:"type:text"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	UserID        uint                   `json:"user_id" gorm:"not null"`
+	ServiceID     *uint                  `json:"service_id" gorm:"index"`
+	ServiceType   string                 `json:"service_type" gorm:"not null"`    // Name of the service at the time of application
+	Status        string                 `json:"status" gorm:"default:'pending'"` // See ApplicationStatusTransitions
+	Progress      string                 `json:"progress" gorm:"default:'0%'"`
+	PaymentStatus string                 `json:"payment_status" gorm:"default:'pending'"` // See PaymentStatusTransitions
+	Amount        float64                `json:"amount" gorm:"default:0"`                 // Total including GST
+	GSTRate       float64                `json:"gst_rate" gorm:"default:0"`               // GST percentage included in Amount
+	Description   string                 `json:"description" gorm:"type:text"`
+	FormData      map[string]interface{} `json:"form_data,omitempty" gorm:"serializer:json;type:jsonb;index:idx_applications_form_data,type:gin"` // Answers to the service's form schema
+	AssignedCA    *uint                  `json:"assigned_ca" gorm:"index"`                                                                        // User ID of the CA assigned to the application
+	Notes         string                 `json:"notes" gorm:"type:text"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt         `json:"-" gorm:"index"`
 
 	// Relationships
-	User        User        `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	AssignedCAUser *User    `json:"assigned_ca_user,omitempty" gorm:"foreignKey:AssignedCA"`
-	Documents   []Document  `json:"documents,omitempty" gorm:"foreignKey:ApplicationID"`
+	User           User               `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Service        *Service           `json:"service,omitempty" gorm:"foreignKey:ServiceID"`
+	AssignedCAUser *User              `json:"assigned_ca_user,omitempty" gorm:"foreignKey:AssignedCA"`
+	Documents      []Document         `json:"documents,omitempty" gorm:"foreignKey:ApplicationID"`
+	Events         []ApplicationEvent `json:"events,omitempty" gorm:"foreignKey:ApplicationID"`
 }
 
 // ApplicationCreateRequest represents application creation request
 type ApplicationCreateRequest struct {
-	ServiceType string  `json:"service_type" binding:"required"`
-	Description string  `json:"description"`
-	Amount      float64 `json:"amount"`
+	ServiceType string                 `json:"service_type" binding:"required"` // Slug or name of an active service
+	Description string                 `json:"description"`
+	FormData    map[string]interface{} `json:"form_data"` // Validated against the service's form schema
 }
 
 // ApplicationUpdateRequest represents application update request (admin only)
@@ -45,21 +50,38 @@ type ApplicationUpdateRequest struct {
 	Amount        float64 `json:"amount"`
 }
 
+// CAApplicationUpdateRequest represents an update made by the assigned CA
+type CAApplicationUpdateRequest struct {
+	Progress string `json:"progress"`
+	Notes    string `json:"notes"`
+}
+
 // ApplicationResponse represents application data in API responses
 type ApplicationResponse struct {
-	ID            uint      `json:"id"`
-	UserID        uint      `json:"user_id"`
-	ServiceType   string    `json:"service_type"`
-	Status        string    `json:"status"`
-	Progress      string    `json:"progress"`
-	PaymentStatus string    `json:"payment_status"`
-	Amount        float64   `json:"amount"`
-	Description   string    `json:"description"`
-	AssignedCA    *uint     `json:"assigned_ca"`
-	Notes         string    `json:"notes"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	User          UserResponse `json:"user,omitempty"`
-	AssignedCAUser *UserResponse `json:"assigned_ca_user,omitempty"`
-	Documents     []DocumentResponse `json:"documents,omitempty"`
-} 
\ No newline at end of file
+	ID                  uint                       `json:"id"`
+	UserID              uint                       `json:"user_id"`
+	ServiceID           *uint                      `json:"service_id"`
+	ServiceType         string                     `json:"service_type"`
+	Status              string                     `json:"status"`
+	Progress            string                     `json:"progress"`
+	PaymentStatus       string                     `json:"payment_status"`
+	Amount              float64                    `json:"amount"`
+	GSTRate             float64                    `json:"gst_rate"`
+	Description         string                     `json:"description"`
+	FormData            map[string]interface{}     `json:"form_data,omitempty"`
+	AssignedCA          *uint                      `json:"assigned_ca"`
+	Notes               string                     `json:"notes"`
+	NextStatuses        []string                   `json:"next_statuses"`         // Statuses the application can move to
+	NextPaymentStatuses []string                   `json:"next_payment_statuses"` // Payment statuses the application can move to
+	CreatedAt           time.Time                  `json:"created_at"`
+	UpdatedAt           time.Time                  `json:"updated_at"`
+	User                UserResponse               `json:"user,omitempty"`
+	AssignedCAUser      *UserResponse              `json:"assigned_ca_user,omitempty"`
+	Documents           []DocumentResponse         `json:"documents,omitempty"`
+	Events              []ApplicationEventResponse `json:"events,omitempty"` // Timeline, oldest first
+
+	// Document checklist, present when the service's requirements were loaded
+	DocumentStatus    string                  `json:"document_status,omitempty"`
+	DocumentChecklist []DocumentChecklistItem `json:"document_checklist,omitempty"`
+	MissingDocuments  []string                `json:"missing_documents,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// ApplicationEvent records a change to a tracked application field
+type ApplicationEvent struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	ApplicationID uint      `json:"application_id" gorm:"not null;index"`
+	ActorID       *uint     `json:"actor_id"`              // nil for changes made by the system
+	Field         string    `json:"field" gorm:"not null"` // status, progress, payment_status, amount, assigned_ca
+	OldValue      string    `json:"old_value"`
+	NewValue      string    `json:"new_value"`
+	CreatedAt     time.Time `json:"created_at" gorm:"index"`
+
+	// Relationships
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
+
+// ApplicationEventResponse represents a timeline entry in API responses
+type ApplicationEventResponse struct {
+	ID        uint      `json:"id"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	ActorID   *uint     `json:"actor_id"`
+	ActorName string    `json:"actor_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditChange is the old and new value of a field changed by an admin request
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditLog records a mutating admin request
+type AuditLog struct {
+	ID         uint                   `json:"id" gorm:"primaryKey"`
+	ActorID    uint                   `json:"actor_id" gorm:"not null;index"`
+	ActorEmail string                 `json:"actor_email"`
+	Method     string                 `json:"method" gorm:"not null"`
+	Route      string                 `json:"route" gorm:"not null"` // Route pattern, e.g. /api/admin/users/:id
+	Path       string                 `json:"path" gorm:"not null"`
+	EntityType string                 `json:"entity_type" gorm:"index"` // users, queries, applications, roles
+	EntityID   string                 `json:"entity_id" gorm:"index"`
+	Changes    map[string]AuditChange `json:"changes" gorm:"serializer:json;type:text"` // Field diff, when the handler reports one
+	Request    string                 `json:"request" gorm:"type:text"`                 // JSON request body with secrets redacted
+	StatusCode int                    `json:"status_code"`
+	IPAddress  string                 `json:"ip_address"`
+	CreatedAt  time.Time              `json:"created_at" gorm:"index"`
+}
@@ -6,15 +6,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// Document kinds
+const (
+	DocumentKindUpload      = "upload"      // supporting document uploaded with an application
+	DocumentKindDeliverable = "deliverable" // work product uploaded by the assigned CA
+	DocumentKindInvoice     = "invoice"     // tax invoice generated when the application is paid
+)
+
 // Document represents uploaded files for applications
 type Document struct {
 	ID            uint           `json:"id" gorm:"primaryKey"`
 	ApplicationID uint           `json:"application_id" gorm:"not null"`
 	FileName      string         `json:"file_name" gorm:"not null"`
-	FilePath      string         `json:"file_path" gorm:"not null"`
+	FilePath      string         `json:"-" gorm:"not null"` // Storage key, resolved by the configured storage backend
 	FileSize      int64          `json:"file_size"`
 	FileType      string         `json:"file_type"`
 	Description   string         `json:"description"`
+	Kind          string         `json:"kind" gorm:"not null;default:'upload'"` // upload, deliverable, invoice
+	RequirementID *uint          `json:"requirement_id" gorm:"index"`           // Document requirement this upload satisfies
+	UploadedBy    *uint          `json:"uploaded_by"`
 	UploadedAt    time.Time      `json:"uploaded_at"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
@@ -31,13 +41,17 @@ type DocumentCreateRequest struct {
 
 // DocumentResponse represents document data in API responses
 type DocumentResponse struct {
-	ID            uint      `json:"id"`
-	ApplicationID uint      `json:"application_id"`
-	FileName      string    `json:"file_name"`
-	FilePath      string    `json:"file_path"`
-	FileSize      int64     `json:"file_size"`
-	FileType      string    `json:"file_type"`
-	Description   string    `json:"description"`
-	UploadedAt    time.Time `json:"uploaded_at"`
-	CreatedAt     time.Time `json:"created_at"`
-} 
\ No newline at end of file
+	ID                   uint      `json:"id"`
+	ApplicationID        uint      `json:"application_id"`
+	FileName             string    `json:"file_name"`
+	FileSize             int64     `json:"file_size"`
+	FileType             string    `json:"file_type"`
+	Description          string    `json:"description"`
+	Kind                 string    `json:"kind"`
+	RequirementID        *uint     `json:"requirement_id"`
+	UploadedBy           *uint     `json:"uploaded_by"`
+	DownloadURL          string    `json:"download_url"`
+	DownloadURLExpiresAt time.Time `json:"download_url_expires_at"`
+	UploadedAt           time.Time `json:"uploaded_at"`
+	CreatedAt            time.Time `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Document checklist statuses
+const (
+	DocumentStatusComplete   = "complete"   // every mandatory requirement has a document
+	DocumentStatusIncomplete = "incomplete" // at least one mandatory requirement is missing
+)
+
+// DocumentRequirement is a document a service needs from the applicant
+type DocumentRequirement struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ServiceID   uint      `json:"service_id" gorm:"not null;uniqueIndex:idx_document_requirements_service_key"`
+	Key         string    `json:"key" gorm:"not null;uniqueIndex:idx_document_requirements_service_key"` // Stable identifier used when tagging uploads
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	Optional    bool      `json:"optional" gorm:"default:false"`
+	SortOrder   int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DocumentRequirementRequest defines a requirement when creating or updating a service
+type DocumentRequirementRequest struct {
+	Key         string `json:"key"` // Derived from the name when empty
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Optional    bool   `json:"optional"`
+}
+
+// DocumentTagRequest tags a document with the requirement it satisfies
+type DocumentTagRequest struct {
+	Requirement string `json:"requirement"` // Requirement key, empty to remove the tag
+}
+
+// DocumentChecklistItem reports whether an application has a document for a requirement
+type DocumentChecklistItem struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Optional    bool   `json:"optional"`
+	Satisfied   bool   `json:"satisfied"`
+	DocumentIDs []uint `json:"document_ids"`
+}
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Form field types a schema can use
+const (
+	FormFieldString  = "string"
+	FormFieldNumber  = "number"
+	FormFieldInteger = "integer"
+	FormFieldBoolean = "boolean"
+)
+
+var formFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FormSchema describes the structured data an application for a service collects.
+// It is the subset of JSON Schema needed for flat forms: an object whose
+// properties are strings, numbers, integers or booleans. Fields not in the
+// schema are rejected.
+type FormSchema struct {
+	Type       string                `json:"type"` // Always "object"
+	Properties map[string]*FormField `json:"properties"`
+	Required   []string              `json:"required,omitempty"`
+}
+
+// FormField is one property of a FormSchema
+type FormField struct {
+	Type        string        `json:"type"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"` // Regular expression a string must match
+	MinLength   *int          `json:"minLength,omitempty"`
+	MaxLength   *int          `json:"maxLength,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	Filterable  bool          `json:"x-filterable,omitempty"` // Admin application listings can filter on the field
+}
+
+// FormValidationError lists the problems with submitted form data by field
+type FormValidationError struct {
+	Fields map[string]string
+}
+
+func (e *FormValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %s", name, e.Fields[name]))
+	}
+	return "invalid form data: " + strings.Join(messages, "; ")
+}
+
+// Check reports whether the schema itself is usable
+func (s *FormSchema) Check() error {
+	if s.Type != "object" {
+		return fmt.Errorf("form schema type must be \"object\"")
+	}
+	if len(s.Properties) == 0 {
+		return fmt.Errorf("form schema needs at least one property")
+	}
+	for name, field := range s.Properties {
+		if !formFieldNamePattern.MatchString(name) {
+			return fmt.Errorf("form field %q must be lowercase letters, digits and underscores", name)
+		}
+		if field == nil {
+			return fmt.Errorf("form field %q has no definition", name)
+		}
+		switch field.Type {
+		case FormFieldString, FormFieldNumber, FormFieldInteger, FormFieldBoolean:
+		default:
+			return fmt.Errorf("form field %q has unsupported type %q", name, field.Type)
+		}
+		if field.Pattern != "" {
+			if field.Type != FormFieldString {
+				return fmt.Errorf("form field %q: pattern only applies to strings", name)
+			}
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				return fmt.Errorf("form field %q has an invalid pattern: %v", name, err)
+			}
+		}
+		for _, value := range field.Enum {
+			if msg := field.checkType(value); msg != "" {
+				return fmt.Errorf("form field %q: enum value %v: %s", name, value, msg)
+			}
+		}
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return fmt.Errorf("required form field %q is not defined", name)
+		}
+	}
+	return nil
+}
+
+// Validate checks submitted form data against the schema. The returned error is
+// a *FormValidationError.
+func (s *FormSchema) Validate(data map[string]interface{}) error {
+	problems := make(map[string]string)
+
+	for _, name := range s.Required {
+		if value, ok := data[name]; !ok || value == nil || value == "" {
+			problems[name] = "is required"
+		}
+	}
+	for name, value := range data {
+		field, ok := s.Properties[name]
+		if !ok {
+			problems[name] = "is not a field of this form"
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		if _, missing := problems[name]; missing {
+			continue
+		}
+		if msg := field.validate(value); msg != "" {
+			problems[name] = msg
+		}
+	}
+
+	if len(problems) > 0 {
+		return &FormValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// checkType reports a problem when a decoded JSON value doesn't have the field's type
+func (f *FormField) checkType(value interface{}) string {
+	switch f.Type {
+	case FormFieldString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case FormFieldNumber:
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case FormFieldInteger:
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return "must be an integer"
+		}
+	case FormFieldBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+	}
+	return ""
+}
+
+// validate checks a decoded JSON value against the field's constraints
+func (f *FormField) validate(value interface{}) string {
+	if msg := f.checkType(value); msg != "" {
+		return msg
+	}
+
+	if len(f.Enum) > 0 {
+		allowed := false
+		for _, option := range f.Enum {
+			if option == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("must be one of %v", f.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if f.MinLength != nil && length < *f.MinLength {
+			return fmt.Sprintf("must be at least %d characters", *f.MinLength)
+		}
+		if f.MaxLength != nil && length > *f.MaxLength {
+			return fmt.Sprintf("must be at most %d characters", *f.MaxLength)
+		}
+		if f.Pattern != "" && !regexp.MustCompile(f.Pattern).MatchString(v) {
+			return "has an invalid format"
+		}
+	case float64:
+		if f.Minimum != nil && v < *f.Minimum {
+			return fmt.Sprintf("must be at least %v", *f.Minimum)
+		}
+		if f.Maximum != nil && v > *f.Maximum {
+			return fmt.Sprintf("must be at most %v", *f.Maximum)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+// Supply types decide how GST is split on an invoice
+const (
+	SupplyIntraState = "intra_state" // CGST and SGST, half the rate each
+	SupplyInterState = "inter_state" // IGST at the full rate
+)
+
+// GSTINPattern matches a 15 character GST identification number. The first two
+// digits are the state code of the registration.
+var GSTINPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+
+// GSTStates maps GST state codes to state and union territory names
+var GSTStates = map[string]string{
+	"01": "Jammu and Kashmir",
+	"02": "Himachal Pradesh",
+	"03": "Punjab",
+	"04": "Chandigarh",
+	"05": "Uttarakhand",
+	"06": "Haryana",
+	"07": "Delhi",
+	"08": "Rajasthan",
+	"09": "Uttar Pradesh",
+	"10": "Bihar",
+	"11": "Sikkim",
+	"12": "Arunachal Pradesh",
+	"13": "Nagaland",
+	"14": "Manipur",
+	"15": "Mizoram",
+	"16": "Tripura",
+	"17": "Meghalaya",
+	"18": "Assam",
+	"19": "West Bengal",
+	"20": "Jharkhand",
+	"21": "Odisha",
+	"22": "Chhattisgarh",
+	"23": "Madhya Pradesh",
+	"24": "Gujarat",
+	"26": "Dadra and Nagar Haveli and Daman and Diu",
+	"27": "Maharashtra",
+	"29": "Karnataka",
+	"30": "Goa",
+	"31": "Lakshadweep",
+	"32": "Kerala",
+	"33": "Tamil Nadu",
+	"34": "Puducherry",
+	"35": "Andaman and Nicobar Islands",
+	"36": "Telangana",
+	"37": "Andhra Pradesh",
+	"38": "Ladakh",
+}
+
+// Invoice is the tax invoice issued for a paid application. Seller and customer
+// details are copied at issue time so the invoice doesn't change afterwards.
+type Invoice struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Number        string    `json:"number" gorm:"uniqueIndex;not null"` // e.g. INV/26-27/00001
+	FinancialYear string    `json:"financial_year" gorm:"not null"`     // e.g. 2026-27
+	Sequence      int       `json:"sequence" gorm:"not null"`           // Position within the financial year
+	ApplicationID uint      `json:"application_id" gorm:"uniqueIndex;not null"`
+	DocumentID    uint      `json:"document_id" gorm:"not null"` // Stored PDF
+	IssuedAt      time.Time `json:"issued_at" gorm:"not null"`
+
+	SellerName    string `json:"seller_name" gorm:"not null"`
+	SellerAddress string `json:"seller_address"`
+	SellerGSTIN   string `json:"seller_gstin" gorm:"not null"`
+	SellerState   string `json:"seller_state" gorm:"not null"` // GST state code
+
+	CustomerName  string `json:"customer_name" gorm:"not null"`
+	CustomerEmail string `json:"customer_email"`
+	CustomerGSTIN string `json:"customer_gstin"`
+	PlaceOfSupply string `json:"place_of_supply" gorm:"not null"` // GST state code
+	SupplyType    string `json:"supply_type" gorm:"not null"`     // intra_state, inter_state
+
+	Description   string  `json:"description" gorm:"not null"` // Service name
+	SAC           string  `json:"sac"`                         // Services accounting code
+	TaxableAmount float64 `json:"taxable_amount" gorm:"not null"`
+	GSTRate       float64 `json:"gst_rate" gorm:"not null"`
+	CGST          float64 `json:"cgst" gorm:"not null;default:0"`
+	SGST          float64 `json:"sgst" gorm:"not null;default:0"`
+	IGST          float64 `json:"igst" gorm:"not null;default:0"`
+	Total         float64 `json:"total" gorm:"not null"` // Application amount, including GST
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Application *Application `json:"-" gorm:"foreignKey:ApplicationID"`
+	Document    *Document    `json:"-" gorm:"foreignKey:DocumentID"`
+}
+
+// InvoiceSequence holds the last invoice number used in a financial year
+type InvoiceSequence struct {
+	FinancialYear string `gorm:"primaryKey"`
+	LastNumber    int    `gorm:"not null;default:0"`
+}
+
+// InvoiceCreateRequest represents a request to issue an application's invoice (admin only)
+type InvoiceCreateRequest struct {
+	ApplicationID uint `json:"application_id" binding:"required"`
+}
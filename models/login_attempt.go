@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt records a password login attempt for auditing and brute-force protection
+type LoginAttempt struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    *uint     `json:"user_id" gorm:"index"` // nil when the email is not registered
+	Email     string    `json:"email" gorm:"index"`
+	IPAddress string    `json:"ip_address" gorm:"index"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"` // invalid_credentials, account_locked, ip_blocked, deactivated
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// LockoutResponse represents a user's login lockout state (admin only)
+type LockoutResponse struct {
+	UserID              uint           `json:"user_id"`
+	Email               string         `json:"email"`
+	FailedLoginAttempts int            `json:"failed_login_attempts"`
+	LockedUntil         *time.Time     `json:"locked_until"`
+	Locked              bool           `json:"locked"`
+	RecentAttempts      []LoginAttempt `json:"recent_attempts"`
+}
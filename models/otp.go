@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// OTP purposes
+const (
+	OTPPurposeVerifyEmail = "verify_email"
+	OTPPurposeVerifyPhone = "verify_phone"
+	OTPPurposeLogin       = "login"
+)
+
+// OTP represents a one-time password sent to a user's email or phone.
+// Only a keyed hash of the code is stored.
+type OTP struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      *uint      `json:"user_id" gorm:"index"`
+	Purpose     string     `json:"purpose" gorm:"not null;index:idx_otp_destination"`
+	Destination string     `json:"destination" gorm:"not null;index:idx_otp_destination"` // Email address or phone number
+	CodeHash    string     `json:"-" gorm:"not null"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	ConsumedAt  *time.Time `json:"consumed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// VerificationSendRequest represents a request to send a verification code
+type VerificationSendRequest struct {
+	Channel string `json:"channel" binding:"required,oneof=email phone"`
+}
+
+// VerificationVerifyRequest represents a verification code submission
+type VerificationVerifyRequest struct {
+	Channel string `json:"channel" binding:"required,oneof=email phone"`
+	Code    string `json:"code" binding:"required"`
+}
+
+// OTPLoginRequest represents a request for a login code sent to a phone
+type OTPLoginRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// OTPLoginVerifyRequest represents a login code submission
+type OTPLoginVerifyRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
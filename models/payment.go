@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Payment order statuses. An order starts as created and becomes paid or failed
+// when the gateway reports the outcome; a failed order can be retried with a new order.
+// A paid order becomes refunded once refunds cover its whole amount.
+const (
+	PaymentOrderCreated  = "created"
+	PaymentOrderPaid     = "paid"
+	PaymentOrderFailed   = "failed"
+	PaymentOrderRefunded = "refunded"
+)
+
+// PaymentCurrency is the currency payments are taken in
+const PaymentCurrency = "INR"
+
+// Payment is a gateway order for an application's amount
+type Payment struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	ApplicationID    uint       `json:"application_id" gorm:"not null;index"`
+	Gateway          string     `json:"gateway" gorm:"not null"`                   // Backend that created the order
+	OrderID          string     `json:"order_id" gorm:"uniqueIndex;not null"`      // Gateway order ID
+	GatewayPaymentID string     `json:"gateway_payment_id" gorm:"index"`           // Gateway payment ID, set once an attempt is reported
+	Amount           float64    `json:"amount" gorm:"not null"`                    // Rupees, including GST
+	RefundedAmount   float64    `json:"refunded_amount" gorm:"not null;default:0"` // Total of processed refunds
+	Currency         string     `json:"currency" gorm:"not null;default:'INR'"`
+	Status           string     `json:"status" gorm:"not null;default:'created'"`
+	Method           string     `json:"method"` // upi, card, netbanking, ...
+	FailureReason    string     `json:"failure_reason"`
+	PaidAt           *time.Time `json:"paid_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Relationships
+	Application *Application `json:"application,omitempty" gorm:"foreignKey:ApplicationID"`
+}
+
+// PaymentWebhookEvent records each webhook delivery so redeliveries are processed once
+type PaymentWebhookEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	EventID   string    `json:"event_id" gorm:"uniqueIndex;not null"`
+	Event     string    `json:"event" gorm:"not null"`
+	OrderID   string    `json:"order_id" gorm:"index"`
+	Payload   string    `json:"payload" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PaymentOrderResponse has what the client needs to open the gateway checkout
+type PaymentOrderResponse struct {
+	Payment  Payment `json:"payment"`
+	KeyID    string  `json:"key_id"`
+	OrderID  string  `json:"order_id"`
+	Amount   int64   `json:"amount"` // Paise
+	Currency string  `json:"currency"`
+}
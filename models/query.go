@@ -14,7 +14,7 @@ type Query struct {
 	Phone       string         `json:"phone" gorm:"not null"`
 	Service     string         `json:"service" gorm:"not null"`
 	Message     string         `json:"message" gorm:"type:text"`
-	Status      string         `json:"status" gorm:"default:'new'"` // new, contacted, converted, closed
+	Status      string         `json:"status" gorm:"default:'new'"` // See QueryStatusTransitions
 	AssignedTo  *uint          `json:"assigned_to"` // Admin user ID
 	Notes       string         `json:"notes" gorm:"type:text"`
 	CreatedAt   time.Time      `json:"created_at"`
@@ -30,7 +30,7 @@ type QueryCreateRequest struct {
 	Name    string `json:"name" binding:"required"`
 	Email   string `json:"email" binding:"required,email"`
 	Phone   string `json:"phone" binding:"required"`
-	Service string `json:"service" binding:"required"`
+	Service string `json:"service" binding:"required"` // Slug or name of an active service
 	Message string `json:"message" binding:"required"`
 }
 
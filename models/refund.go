@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Refund statuses. A refund is requested, then either rejected or approved by
+// finance staff. Approval sends it to the gateway, after which it is processed or
+// failed; a gateway that processes refunds later reports the outcome by webhook.
+const (
+	RefundStatusRequested = "requested"
+	RefundStatusRejected  = "rejected"
+	RefundStatusApproved  = "approved"
+	RefundStatusProcessed = "processed"
+	RefundStatusFailed    = "failed"
+)
+
+// RefundOpenStatuses are the statuses whose amount is reserved against the payment
+var RefundOpenStatuses = []string{RefundStatusRequested, RefundStatusApproved, RefundStatusProcessed}
+
+// Refund is a request to return part or all of a paid payment
+type Refund struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	PaymentID       uint       `json:"payment_id" gorm:"not null;index"`
+	ApplicationID   uint       `json:"application_id" gorm:"not null;index"`
+	Amount          float64    `json:"amount" gorm:"not null"` // Rupees
+	Reason          string     `json:"reason" gorm:"type:text;not null"`
+	Status          string     `json:"status" gorm:"not null;default:'requested';index"`
+	RequestedBy     uint       `json:"requested_by" gorm:"not null"`
+	ReviewedBy      *uint      `json:"reviewed_by"` // Finance user who approved or rejected the request
+	ReviewNote      string     `json:"review_note" gorm:"type:text"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+	GatewayRefundID string     `json:"gateway_refund_id" gorm:"index"`
+	FailureReason   string     `json:"failure_reason"`
+	ProcessedAt     *time.Time `json:"processed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relationships
+	Payment         *Payment `json:"payment,omitempty" gorm:"foreignKey:PaymentID"`
+	RequestedByUser *User    `json:"-" gorm:"foreignKey:RequestedBy"`
+	ReviewedByUser  *User    `json:"-" gorm:"foreignKey:ReviewedBy"`
+}
+
+// RefundCreateRequest represents a refund request for a payment
+type RefundCreateRequest struct {
+	PaymentID uint    `json:"payment_id" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"` // Rupees; at most the payment's unrefunded amount
+	Reason    string  `json:"reason" binding:"required"`
+}
+
+// RefundReviewRequest represents a finance decision on a refund request
+type RefundReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// ReconciliationRow compares an application's amount with its recorded payments and refunds
+type ReconciliationRow struct {
+	ApplicationID uint     `json:"application_id"`
+	UserID        uint     `json:"user_id"`
+	ServiceType   string   `json:"service_type"`
+	PaymentStatus string   `json:"payment_status"`
+	Amount        float64  `json:"amount"`
+	Paid          float64  `json:"paid"`     // Paid gateway payments
+	Refunded      float64  `json:"refunded"` // Processed refunds
+	Net           float64  `json:"net"`      // Paid minus refunded
+	Expected      float64  `json:"expected"` // What net should be for the payment status
+	Difference    float64  `json:"difference"`
+	Issues        []string `json:"issues" gorm:"-"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// Permissions granted by roles
+const (
+	PermissionAll                  = "*" // every permission, held by the admin role
+	PermissionQueriesRead          = "queries:read"
+	PermissionQueriesUpdate        = "queries:update"
+	PermissionApplicationsRead     = "applications:read"
+	PermissionApplicationsUpdate   = "applications:update"
+	PermissionApplicationsAssigned = "applications:assigned" // work on applications assigned to you through the CA portal
+	PermissionPaymentsRead         = "payments:read"
+	PermissionPaymentsRefund       = "payments:refund"
+	PermissionUsersRead            = "users:read"
+	PermissionUsersManage          = "users:manage"
+	PermissionRolesManage          = "roles:manage"
+	PermissionAuditRead            = "audit:read"
+	PermissionServicesManage       = "services:manage"
+	PermissionInvoicesIssue        = "invoices:issue"
+)
+
+// AllPermissions lists every permission that can be granted to a role
+var AllPermissions = []string{
+	PermissionQueriesRead,
+	PermissionQueriesUpdate,
+	PermissionApplicationsRead,
+	PermissionApplicationsUpdate,
+	PermissionApplicationsAssigned,
+	PermissionPaymentsRead,
+	PermissionPaymentsRefund,
+	PermissionUsersRead,
+	PermissionUsersManage,
+	PermissionRolesManage,
+	PermissionAuditRead,
+	PermissionServicesManage,
+	PermissionInvoicesIssue,
+}
+
+// Built-in roles
+const (
+	RoleAdmin   = "admin"
+	RoleUser    = "user"
+	RoleCA      = "ca"
+	RoleSupport = "support"
+	RoleFinance = "finance"
+)
+
+// Role is a named set of permissions assigned to users through User.Role
+type Role struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;not null"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions" gorm:"serializer:json;type:text;not null;default:'[]'"`
+	IsSystem    bool      `json:"is_system" gorm:"default:false"` // Built-in roles can't be deleted
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HasPermission reports whether the role grants a permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == PermissionAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleCreateRequest represents a new role (admin only)
+type RoleCreateRequest struct {
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// RoleUpdateRequest represents a change to a role's description or permissions (admin only)
+type RoleUpdateRequest struct {
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleAssignRequest assigns a role to a user (admin only)
+type RoleAssignRequest struct {
+	Role string `json:"role" binding:"required"`
+}
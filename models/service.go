@@ -0,0 +1,58 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultGSTRate is the GST percentage applied when a service doesn't set one
+const DefaultGSTRate = 18.0
+
+// Service is an offering from the service catalog that applications and queries refer to
+type Service struct {
+	ID          uint        `json:"id" gorm:"primaryKey"`
+	Name        string      `json:"name" gorm:"uniqueIndex;not null"`
+	Slug        string      `json:"slug" gorm:"uniqueIndex;not null"`
+	Description string      `json:"description" gorm:"type:text"`
+	BasePrice   float64     `json:"base_price" gorm:"not null;default:0"` // Price before GST
+	GSTRate     float64     `json:"gst_rate" gorm:"not null;default:18"`  // GST percentage
+	SLADays     int         `json:"sla_days" gorm:"default:0"`            // Target turnaround in days, 0 when not committed
+	IsActive    bool        `json:"is_active" gorm:"default:true"`
+	FormSchema  *FormSchema `json:"form_schema" gorm:"serializer:json;type:text"` // Structured data applications collect, nil for none
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+
+	// Relationships
+	DocumentRequirements []DocumentRequirement `json:"document_requirements" gorm:"foreignKey:ServiceID"`
+}
+
+// TotalPrice returns the price including GST, rounded to the paisa
+func (s *Service) TotalPrice() float64 {
+	return math.Round(s.BasePrice*(100+s.GSTRate)) / 100
+}
+
+// ServiceCreateRequest represents service creation request (admin only)
+type ServiceCreateRequest struct {
+	Name                 string                       `json:"name" binding:"required"`
+	Slug                 string                       `json:"slug"` // Derived from the name when empty
+	Description          string                       `json:"description"`
+	BasePrice            float64                      `json:"base_price" binding:"gte=0"`
+	GSTRate              *float64                     `json:"gst_rate" binding:"omitempty,gte=0,lte=100"` // Defaults to DefaultGSTRate
+	DocumentRequirements []DocumentRequirementRequest `json:"document_requirements" binding:"dive"`
+	FormSchema           *FormSchema                  `json:"form_schema"`
+	SLADays              int                          `json:"sla_days" binding:"gte=0"`
+	IsActive             *bool                        `json:"is_active"` // Defaults to true
+}
+
+// ServiceUpdateRequest represents service update request (admin only); omitted fields are unchanged
+type ServiceUpdateRequest struct {
+	Name                 *string                      `json:"name"`
+	Slug                 *string                      `json:"slug"`
+	Description          *string                      `json:"description"`
+	BasePrice            *float64                     `json:"base_price" binding:"omitempty,gte=0"`
+	GSTRate              *float64                     `json:"gst_rate" binding:"omitempty,gte=0,lte=100"`
+	DocumentRequirements []DocumentRequirementRequest `json:"document_requirements" binding:"omitempty,dive"` // Replaces the whole list when present
+	FormSchema           *FormSchema                  `json:"form_schema"`                                    // An empty object removes the form
+	SLADays              *int                         `json:"sla_days" binding:"omitempty,gte=0"`
+	IsActive             *bool                        `json:"is_active"`
+}
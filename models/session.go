@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Session represents a refresh token issued to a user.
+// Refresh tokens are rotated on every use; only their SHA-256 hash is stored.
+type Session struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	UserID       uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"` // Session created when this one was rotated
+	UserAgent    string     `json:"user_agent"`
+	IPAddress    string     `json:"ip_address"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// RefreshTokenRequest represents a token refresh or logout request
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Application statuses
+const (
+	ApplicationStatusPending    = "pending"
+	ApplicationStatusInProgress = "in_progress"
+	ApplicationStatusCompleted  = "completed"
+	ApplicationStatusCancelled  = "cancelled"
+)
+
+// Payment statuses
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusRefunded = "refunded"
+)
+
+// Query statuses
+const (
+	QueryStatusNew       = "new"
+	QueryStatusContacted = "contacted"
+	QueryStatusConverted = "converted"
+	QueryStatusClosed    = "closed"
+)
+
+// StateMachine maps each state to the states it may move to next.
+// A state with no entries is terminal.
+type StateMachine map[string][]string
+
+// ApplicationStatusTransitions are the allowed changes to Application.Status
+var ApplicationStatusTransitions = StateMachine{
+	ApplicationStatusPending:    {ApplicationStatusInProgress, ApplicationStatusCancelled},
+	ApplicationStatusInProgress: {ApplicationStatusCompleted, ApplicationStatusCancelled},
+	ApplicationStatusCompleted:  {},
+	ApplicationStatusCancelled:  {ApplicationStatusPending}, // reopen
+}
+
+// PaymentStatusTransitions are the allowed changes to Application.PaymentStatus.
+// Only processed refunds move an application from paid to refunded.
+var PaymentStatusTransitions = StateMachine{
+	PaymentStatusPending:  {PaymentStatusPaid},
+	PaymentStatusPaid:     {},
+	PaymentStatusRefunded: {},
+}
+
+// QueryStatusTransitions are the allowed changes to Query.Status
+var QueryStatusTransitions = StateMachine{
+	QueryStatusNew:       {QueryStatusContacted, QueryStatusConverted, QueryStatusClosed},
+	QueryStatusContacted: {QueryStatusConverted, QueryStatusClosed},
+	QueryStatusConverted: {},
+	QueryStatusClosed:    {QueryStatusContacted}, // reopen
+}
+
+// IsValid reports whether state is a known state
+func (m StateMachine) IsValid(state string) bool {
+	_, ok := m[state]
+	return ok
+}
+
+// Next returns the states reachable from the given state. A row stuck in an
+// unknown state (e.g. a typo stored before validation existed) may move to any
+// known state so it can be repaired.
+func (m StateMachine) Next(from string) []string {
+	if next, ok := m[from]; ok {
+		return next
+	}
+	states := make([]string, 0, len(m))
+	for state := range m {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	return states
+}
+
+// Validate checks that moving from one state to another is allowed.
+// Staying in the same state is always allowed.
+func (m StateMachine) Validate(from, to string) error {
+	if !m.IsValid(to) {
+		return fmt.Errorf("%q is not a valid state", to)
+	}
+	if from == to {
+		return nil
+	}
+	for _, state := range m.Next(from) {
+		if state == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot move from %q to %q", from, to)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// RecoveryCode represents a single-use two-factor recovery code.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TwoFactorCodeRequest represents a request confirmed with a TOTP code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorDisableRequest represents a request to turn off two-factor authentication
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+// TwoFactorLoginRequest represents the second step of a two-factor login
+type TwoFactorLoginRequest struct {
+	Token string `json:"token" binding:"required"` // Pending token returned by the first login step
+	Code  string `json:"code" binding:"required"`  // TOTP code or recovery code
+}
@@ -8,16 +8,27 @@ import (
 
 // User represents a registered user in the system
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Phone     string         `json:"phone" gorm:"uniqueIndex;not null"`
-	Name      string         `json:"name" gorm:"not null"`
-	Password  string         `json:"-" gorm:"not null"` // "-" means don't include in JSON
-	Role      string         `json:"role" gorm:"default:'user'"` // user, admin
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                  uint           `json:"id" gorm:"primaryKey"`
+	Email               string         `json:"email" gorm:"uniqueIndex;not null"`
+	Phone               string         `json:"phone" gorm:"uniqueIndex;not null"`
+	Name                string         `json:"name" gorm:"not null"`
+	Password            string         `json:"-" gorm:"not null"`          // "-" means don't include in JSON
+	Role                string         `json:"role" gorm:"default:'user'"` // user, admin
+	IsActive            bool           `json:"is_active" gorm:"default:true"`
+	MustChangePassword  bool           `json:"must_change_password" gorm:"default:false"`
+	EmailVerifiedAt     *time.Time     `json:"email_verified_at"`
+	PhoneVerifiedAt     *time.Time     `json:"phone_verified_at"`
+	FailedLoginAttempts int            `json:"-" gorm:"default:0"`
+	LockedUntil         *time.Time     `json:"-"`
+	TOTPEnabled         bool           `json:"totp_enabled" gorm:"default:false"`
+	TOTPSecret          string         `json:"-"`
+	TOTPPendingSecret   string         `json:"-"`     // Secret awaiting confirmation during enrollment
+	TOTPLastUsedStep    int64          `json:"-"`     // Last accepted time step, to reject code reuse
+	State               string         `json:"state"` // GST state code, used as the place of supply on invoices
+	GSTIN               string         `json:"gstin"` // Customer's GST registration, printed on invoices
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relationships
 	Applications []Application `json:"applications,omitempty" gorm:"foreignKey:UserID"`
@@ -39,17 +50,36 @@ type UserRegisterRequest struct {
 
 // UserUpdateRequest represents user profile update request
 type UserUpdateRequest struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
+	Name  string  `json:"name"`
+	Phone string  `json:"phone"`
+	State *string `json:"state"` // GST state code such as "27"; empty clears it
+	GSTIN *string `json:"gstin"` // Sets state from its first two digits; empty clears it
+}
+
+// ChangePasswordRequest represents a password change by the logged-in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// IsVerified reports whether both the user's email and phone have been verified
+func (u *User) IsVerified() bool {
+	return u.EmailVerifiedAt != nil && u.PhoneVerifiedAt != nil
 }
 
 // UserResponse represents user data in API responses
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Name      string    `json:"name"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-} 
\ No newline at end of file
+	ID                 uint      `json:"id"`
+	Email              string    `json:"email"`
+	Phone              string    `json:"phone"`
+	Name               string    `json:"name"`
+	Role               string    `json:"role"`
+	IsActive           bool      `json:"is_active"`
+	MustChangePassword bool      `json:"must_change_password"`
+	EmailVerified      bool      `json:"email_verified"`
+	PhoneVerified      bool      `json:"phone_verified"`
+	TwoFactorEnabled   bool      `json:"two_factor_enabled"`
+	State              string    `json:"state"`
+	GSTIN              string    `json:"gstin"`
+	CreatedAt          time.Time `json:"created_at"`
+}
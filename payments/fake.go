@@ -0,0 +1,311 @@
+package payments
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeGateway is an in-memory server implementing the parts of the Razorpay API
+// the application uses. Paying an order through it delivers a signed webhook,
+// so the whole payment flow can be exercised locally and in tests.
+// Intended for local development and testing.
+type FakeGateway struct {
+	keyID         string
+	keySecret     string
+	webhookSecret string
+	webhookURL    string
+	client        *http.Client
+
+	mu       sync.Mutex
+	orders   map[string]*Order
+	payments map[string]*PaymentEntity
+	refunded map[string]int64  // Paise refunded per payment
+	receipts map[string]Refund // Refunds by payment ID and receipt
+}
+
+// NewFakeGateway creates a fake gateway that sends webhooks to webhookURL
+func NewFakeGateway(keyID, keySecret, webhookSecret, webhookURL string) *FakeGateway {
+	return &FakeGateway{
+		keyID:         keyID,
+		keySecret:     keySecret,
+		webhookSecret: webhookSecret,
+		webhookURL:    webhookURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		orders:        make(map[string]*Order),
+		payments:      make(map[string]*PaymentEntity),
+		refunded:      make(map[string]int64),
+		receipts:      make(map[string]Refund),
+	}
+}
+
+// Handler returns the gateway's HTTP API:
+//
+//	POST /v1/orders          create an order (basic auth)
+//	GET  /v1/orders/{id}     fetch an order (basic auth)
+//	POST /v1/orders/{id}/pay simulate checkout; {"status": "captured"|"failed", "method": "upi"}
+//	POST /v1/payments/{id}/refund    refund a captured payment; {"amount": 1000, "receipt": "..."} (basic auth)
+//	POST /v1/payments/{id}/redeliver send the payment's webhook again
+//
+// Refunds are processed immediately, so no refund webhooks are sent.
+func (f *FakeGateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/orders", f.authenticated(f.createOrder))
+	mux.HandleFunc("GET /v1/orders/{id}", f.authenticated(f.getOrder))
+	mux.HandleFunc("POST /v1/orders/{id}/pay", f.payOrder)
+	mux.HandleFunc("POST /v1/payments/{id}/refund", f.authenticated(f.refundPayment))
+	mux.HandleFunc("POST /v1/payments/{id}/redeliver", f.redeliver)
+	return mux
+}
+
+// authenticated requires the gateway key pair as HTTP basic auth
+func (f *FakeGateway) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keyID, keySecret, ok := r.BasicAuth()
+		if !ok || keyID != f.keyID || keySecret != f.keySecret {
+			writeFakeError(w, http.StatusUnauthorized, "Authentication failed")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (f *FakeGateway) createOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount   int64             `json:"amount"`
+		Currency string            `json:"currency"`
+		Receipt  string            `json:"receipt"`
+		Notes    map[string]string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Amount < 100 {
+		writeFakeError(w, http.StatusBadRequest, "Order amount less than minimum amount allowed")
+		return
+	}
+	if req.Currency == "" {
+		req.Currency = "INR"
+	}
+
+	order := &Order{
+		ID:       "order_" + fakeID(),
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Receipt:  req.Receipt,
+		Status:   "created",
+		Notes:    req.Notes,
+	}
+	f.mu.Lock()
+	f.orders[order.ID] = order
+	f.mu.Unlock()
+
+	writeFakeJSON(w, http.StatusOK, order)
+}
+
+func (f *FakeGateway) getOrder(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	order, ok := f.orders[r.PathValue("id")]
+	f.mu.Unlock()
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "The id provided does not exist")
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, order)
+}
+
+func (f *FakeGateway) payOrder(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status string `json:"status"`
+		Method string `json:"method"`
+	}
+	// An empty body pays the order
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.Status == "" {
+		req.Status = "captured"
+	}
+	if req.Status != "captured" && req.Status != "failed" {
+		writeFakeError(w, http.StatusBadRequest, "status must be captured or failed")
+		return
+	}
+	if req.Method == "" {
+		req.Method = "upi"
+	}
+
+	f.mu.Lock()
+	order, ok := f.orders[r.PathValue("id")]
+	if !ok {
+		f.mu.Unlock()
+		writeFakeError(w, http.StatusNotFound, "The id provided does not exist")
+		return
+	}
+	if order.Status == "paid" {
+		f.mu.Unlock()
+		writeFakeError(w, http.StatusBadRequest, "Order is already paid")
+		return
+	}
+	payment := &PaymentEntity{
+		ID:       "pay_" + fakeID(),
+		OrderID:  order.ID,
+		Amount:   order.Amount,
+		Currency: order.Currency,
+		Status:   req.Status,
+		Method:   req.Method,
+	}
+	if req.Status == "failed" {
+		payment.ErrorDescription = "Payment was declined by the customer's bank"
+	} else {
+		order.Status = "paid"
+	}
+	f.payments[payment.ID] = payment
+	f.mu.Unlock()
+
+	event := EventPaymentCaptured
+	if req.Status == "failed" {
+		event = EventPaymentFailed
+	}
+	webhookStatus, err := f.SendWebhook(event, *payment)
+	if err != nil {
+		log.Printf("Fake gateway: webhook for %s failed: %v", payment.ID, err)
+	}
+
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"payment":        payment,
+		"webhook_status": webhookStatus,
+	})
+}
+
+func (f *FakeGateway) refundPayment(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Amount  int64             `json:"amount"`
+		Receipt string            `json:"receipt"`
+		Notes   map[string]string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	payment, ok := f.payments[r.PathValue("id")]
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "The id provided does not exist")
+		return
+	}
+	if payment.Status != "captured" {
+		writeFakeError(w, http.StatusBadRequest, "Only captured payments can be refunded")
+		return
+	}
+	receiptKey := payment.ID + "/" + req.Receipt
+	if refund, ok := f.receipts[receiptKey]; ok && req.Receipt != "" {
+		writeFakeJSON(w, http.StatusOK, refund)
+		return
+	}
+	// Without an amount the rest of the payment is refunded
+	if req.Amount == 0 {
+		req.Amount = payment.Amount - f.refunded[payment.ID]
+	}
+	if req.Amount <= 0 || f.refunded[payment.ID]+req.Amount > payment.Amount {
+		writeFakeError(w, http.StatusBadRequest, "The refund amount provided is greater than amount captured")
+		return
+	}
+	f.refunded[payment.ID] += req.Amount
+
+	refund := Refund{
+		ID:        "rfnd_" + fakeID(),
+		PaymentID: payment.ID,
+		Amount:    req.Amount,
+		Currency:  payment.Currency,
+		Status:    RefundProcessed,
+		Receipt:   req.Receipt,
+		Notes:     req.Notes,
+	}
+	if req.Receipt != "" {
+		f.receipts[receiptKey] = refund
+	}
+	writeFakeJSON(w, http.StatusOK, refund)
+}
+
+func (f *FakeGateway) redeliver(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	payment, ok := f.payments[r.PathValue("id")]
+	f.mu.Unlock()
+	if !ok {
+		writeFakeError(w, http.StatusNotFound, "The id provided does not exist")
+		return
+	}
+
+	event := EventPaymentCaptured
+	if payment.Status == "failed" {
+		event = EventPaymentFailed
+	}
+	webhookStatus, err := f.SendWebhook(event, *payment)
+	if err != nil {
+		writeFakeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{"webhook_status": webhookStatus})
+}
+
+// SendWebhook delivers a signed event for a payment and returns the response status.
+// Calling it again with the same payment simulates the gateway retrying a delivery.
+func (f *FakeGateway) SendWebhook(event string, payment PaymentEntity) (int, error) {
+	if f.webhookURL == "" {
+		return 0, fmt.Errorf("no webhook URL configured")
+	}
+
+	var body WebhookEvent
+	body.Entity = "event"
+	body.Event = event
+	body.Contains = []string{"payment"}
+	body.Payload.Payment.Entity = payment
+	body.CreatedAt = time.Now().Unix()
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.webhookURL, bytes.NewReader(encoded))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(encoded, f.webhookSecret))
+	// The event ID is stable per payment and event so redeliveries are recognised
+	req.Header.Set(EventIDHeader, fmt.Sprintf("evt_%s_%s", payment.ID, event))
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// fakeID returns a random identifier in the style of gateway IDs
+func fakeID() string {
+	b := make([]byte, 7)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, description string) {
+	writeFakeJSON(w, status, map[string]interface{}{
+		"error": map[string]string{"code": "BAD_REQUEST_ERROR", "description": description},
+	})
+}
@@ -0,0 +1,127 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+
+	"bharat-seva-space/config"
+)
+
+// DefaultFakeGatewayURL is where `bharat-seva fake-gateway` listens by default
+const DefaultFakeGatewayURL = "http://localhost:9090"
+
+// Order is a payment order created with the gateway. Amounts are in paise.
+type Order struct {
+	ID       string            `json:"id"`
+	Amount   int64             `json:"amount"`
+	Currency string            `json:"currency"`
+	Receipt  string            `json:"receipt"`
+	Status   string            `json:"status"`
+	Notes    map[string]string `json:"notes,omitempty"`
+}
+
+// Refund is a refund of a captured payment. Amounts are in paise.
+type Refund struct {
+	ID        string            `json:"id"`
+	PaymentID string            `json:"payment_id"`
+	Amount    int64             `json:"amount"`
+	Currency  string            `json:"currency"`
+	Status    string            `json:"status"` // pending, processed or failed
+	Receipt   string            `json:"receipt,omitempty"`
+	Notes     map[string]string `json:"notes,omitempty"`
+}
+
+// Gateway refund statuses
+const (
+	RefundPending   = "pending"
+	RefundProcessed = "processed"
+	RefundFailed    = "failed"
+)
+
+// Gateway creates payment orders that customers pay through the gateway's checkout,
+// and refunds captured payments
+type Gateway interface {
+	Name() string
+	KeyID() string // Public key the checkout is opened with
+	CreateOrder(ctx context.Context, amount int64, currency, receipt string, notes map[string]string) (*Order, error)
+	// Refund refunds a payment. The receipt identifies the refund so a retried
+	// request isn't refunded twice.
+	Refund(ctx context.Context, paymentID string, amount int64, receipt string, notes map[string]string) (*Refund, error)
+}
+
+// GatewayError is an error response from the gateway
+type GatewayError struct {
+	StatusCode  int
+	Description string
+}
+
+func (e *GatewayError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("payment gateway returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("payment gateway returned %d: %s", e.StatusCode, e.Description)
+}
+
+// IsRejected reports whether err is the gateway refusing a request. Other errors,
+// such as timeouts and server errors, leave it unknown whether the request was carried out.
+func IsRejected(err error) bool {
+	var gatewayErr *GatewayError
+	return errors.As(err, &gatewayErr) && gatewayErr.StatusCode >= 400 && gatewayErr.StatusCode < 500
+}
+
+// Default is the payment gateway used by the application, nil when payments are disabled
+var Default Gateway
+
+// InitPayments initializes the payment gateway selected by PAYMENT_BACKEND
+func InitPayments() error {
+	paymentConfig := config.GetPaymentConfig()
+
+	switch paymentConfig["backend"] {
+	case "", "none":
+		Default = nil
+	case "razorpay", "fake":
+		if paymentConfig["key_id"] == "" || paymentConfig["key_secret"] == "" {
+			return fmt.Errorf("PAYMENT_KEY_ID and PAYMENT_KEY_SECRET are required for the %s payment backend", paymentConfig["backend"])
+		}
+		if paymentConfig["webhook_secret"] == "" {
+			return fmt.Errorf("PAYMENT_WEBHOOK_SECRET is required for the %s payment backend", paymentConfig["backend"])
+		}
+		baseURL := paymentConfig["gateway_url"]
+		if baseURL == "" {
+			baseURL = DefaultRazorpayURL
+			if paymentConfig["backend"] == "fake" {
+				baseURL = DefaultFakeGatewayURL
+			}
+		}
+		Default = NewRazorpayGateway(paymentConfig["backend"], baseURL, paymentConfig["key_id"], paymentConfig["key_secret"])
+	default:
+		return fmt.Errorf("unknown payment backend: %s", paymentConfig["backend"])
+	}
+
+	return nil
+}
+
+// ToPaise converts a rupee amount to the smallest currency unit
+func ToPaise(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Sign computes the hex HMAC-SHA256 signature the gateway sends with webhooks
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a webhook body against its signature header
+func VerifyWebhookSignature(body []byte, signature, secret string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(body, secret)), []byte(signature))
+}
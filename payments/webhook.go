@@ -0,0 +1,43 @@
+package payments
+
+// Webhook events the application handles
+const (
+	EventPaymentCaptured = "payment.captured"
+	EventPaymentFailed   = "payment.failed"
+	EventOrderPaid       = "order.paid"
+	EventRefundProcessed = "refund.processed"
+	EventRefundFailed    = "refund.failed"
+)
+
+// Webhook headers sent by the gateway
+const (
+	SignatureHeader = "X-Razorpay-Signature"
+	EventIDHeader   = "X-Razorpay-Event-Id"
+)
+
+// PaymentEntity is a payment attempt as reported in webhooks. Amounts are in paise.
+type PaymentEntity struct {
+	ID               string `json:"id"`
+	OrderID          string `json:"order_id"`
+	Amount           int64  `json:"amount"`
+	Currency         string `json:"currency"`
+	Status           string `json:"status"`
+	Method           string `json:"method"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// WebhookEvent is the body of a gateway webhook
+type WebhookEvent struct {
+	Entity   string   `json:"entity"`
+	Event    string   `json:"event"`
+	Contains []string `json:"contains"`
+	Payload  struct {
+		Payment struct {
+			Entity PaymentEntity `json:"entity"`
+		} `json:"payment"`
+		Refund struct {
+			Entity Refund `json:"entity"`
+		} `json:"refund"`
+	} `json:"payload"`
+	CreatedAt int64 `json:"created_at"`
+}
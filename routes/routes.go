@@ -3,6 +3,7 @@ package routes
 import (
 	"bharat-seva-space/handlers"
 	"bharat-seva-space/middleware"
+	"bharat-seva-space/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,6 +38,23 @@ func SetupRoutes() *gin.Engine {
 			// Authentication
 			public.POST("/auth/register", handlers.Register)
 			public.POST("/auth/login", handlers.Login)
+			public.POST("/auth/refresh", handlers.RefreshToken)
+			public.POST("/auth/logout", handlers.Logout)
+			public.POST("/auth/forgot-password", handlers.ForgotPassword)
+			public.POST("/auth/reset-password", handlers.ResetPassword)
+			public.POST("/auth/otp/request", handlers.RequestLoginOTP)
+			public.POST("/auth/otp/verify", handlers.VerifyLoginOTP)
+			public.POST("/auth/2fa/verify", handlers.VerifyTwoFactorLogin)
+
+			// Service catalog
+			public.GET("/services", handlers.GetServices)
+			public.GET("/services/:slug", handlers.GetService)
+
+			// Signed document downloads
+			public.GET("/documents/:id/download", handlers.DownloadSignedDocument)
+
+			// Payment gateway webhooks (authenticated by signature)
+			public.POST("/payments/webhook", handlers.PaymentWebhook)
 		}
 
 		// User routes (authentication required)
@@ -46,6 +64,17 @@ func SetupRoutes() *gin.Engine {
 			// User profile
 			user.GET("/profile", handlers.GetProfile)
 			user.PUT("/profile", handlers.UpdateProfile)
+			user.PUT("/password", handlers.ChangePassword)
+
+			// Email and phone verification
+			user.POST("/verification/send", handlers.SendVerificationCode)
+			user.POST("/verification/verify", handlers.VerifyContact)
+
+			// Two-factor authentication
+			user.POST("/2fa/setup", handlers.SetupTwoFactor)
+			user.POST("/2fa/enable", handlers.EnableTwoFactor)
+			user.POST("/2fa/disable", handlers.DisableTwoFactor)
+			user.POST("/2fa/recovery-codes", handlers.RegenerateRecoveryCodes)
 			
 			// Dashboard
 			user.GET("/dashboard", handlers.GetDashboardData)
@@ -55,29 +84,92 @@ func SetupRoutes() *gin.Engine {
 			user.POST("/applications", handlers.CreateApplication)
 			user.GET("/applications/:id", handlers.GetApplication)
 			user.GET("/applications/stats", handlers.GetApplicationStats)
+
+			// Application documents
+			user.GET("/applications/:id/documents", handlers.GetApplicationDocuments)
+			user.POST("/applications/:id/documents", handlers.UploadDocument)
+			user.GET("/applications/:id/documents/:doc_id", handlers.DownloadDocument)
+			user.PUT("/applications/:id/documents/:doc_id", handlers.TagDocument)
+			user.DELETE("/applications/:id/documents/:doc_id", handlers.DeleteDocument)
+
+			// Application payments
+			user.POST("/applications/:id/payments", handlers.CreatePaymentOrder)
+			user.GET("/applications/:id/payments", handlers.GetApplicationPayments)
+			user.POST("/applications/:id/refunds", handlers.CreateRefundRequest)
+			user.GET("/applications/:id/refunds", handlers.GetApplicationRefunds)
+			user.GET("/applications/:id/invoice", handlers.DownloadApplicationInvoice)
+		}
+
+		// CA routes (only applications assigned to the current CA)
+		ca := api.Group("/ca")
+		ca.Use(middleware.AuthMiddleware())
+		ca.Use(middleware.RequirePermission(models.PermissionApplicationsAssigned))
+		{
+			ca.GET("/applications", handlers.GetCAApplications)
+			ca.GET("/applications/:id", handlers.GetCAApplication)
+			ca.PUT("/applications/:id", handlers.UpdateCAApplication)
+			ca.GET("/applications/:id/documents", handlers.GetCAApplicationDocuments)
+			ca.POST("/applications/:id/documents", handlers.UploadCADeliverable)
+			ca.GET("/applications/:id/documents/:doc_id", handlers.DownloadCADocument)
 		}
 
-		// Admin routes (admin authentication required)
+		// Admin routes (staff authentication required, plus a permission per route)
 		admin := api.Group("/admin")
 		admin.Use(middleware.AuthMiddleware())
 		admin.Use(middleware.AdminMiddleware())
+		admin.Use(middleware.AuditLog())
 		{
 			// Query management
-			admin.GET("/queries", handlers.GetQueries)
-			admin.GET("/queries/:id", handlers.GetQuery)
-			admin.PUT("/queries/:id", handlers.UpdateQuery)
-			admin.GET("/queries/stats", handlers.GetQueryStats)
+			admin.GET("/queries", middleware.RequirePermission(models.PermissionQueriesRead), handlers.GetQueries)
+			admin.GET("/queries/:id", middleware.RequirePermission(models.PermissionQueriesRead), handlers.GetQuery)
+			admin.PUT("/queries/:id", middleware.RequirePermission(models.PermissionQueriesUpdate), handlers.UpdateQuery)
+			admin.GET("/queries/stats", middleware.RequirePermission(models.PermissionQueriesRead), handlers.GetQueryStats)
 			
 			// User management
-			admin.GET("/users", handlers.GetAllUsers)
-			admin.GET("/users/:id", handlers.GetUser)
-			admin.PUT("/users/:id", handlers.UpdateUser)
-			admin.GET("/users/stats", handlers.GetUserStats)
+			admin.GET("/users", middleware.RequirePermission(models.PermissionUsersRead), handlers.GetAllUsers)
+			admin.GET("/users/:id", middleware.RequirePermission(models.PermissionUsersRead), handlers.GetUser)
+			admin.PUT("/users/:id", middleware.RequirePermission(models.PermissionUsersManage), handlers.UpdateUser)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermissionRolesManage), handlers.AssignUserRole)
+			admin.POST("/users/:id/force-password-change", middleware.RequirePermission(models.PermissionUsersManage), handlers.ForcePasswordChange)
+			admin.GET("/users/:id/lockout", middleware.RequirePermission(models.PermissionUsersRead), handlers.GetUserLockout)
+			admin.DELETE("/users/:id/lockout", middleware.RequirePermission(models.PermissionUsersManage), handlers.ClearUserLockout)
+			admin.GET("/users/stats", middleware.RequirePermission(models.PermissionUsersRead), handlers.GetUserStats)
+
+			// Role management
+			admin.GET("/roles", middleware.RequirePermission(models.PermissionRolesManage), handlers.GetRoles)
+			admin.POST("/roles", middleware.RequirePermission(models.PermissionRolesManage), handlers.CreateRole)
+			admin.PUT("/roles/:id", middleware.RequirePermission(models.PermissionRolesManage), handlers.UpdateRole)
+			admin.DELETE("/roles/:id", middleware.RequirePermission(models.PermissionRolesManage), handlers.DeleteRole)
 			
 			// Application management
-			admin.GET("/applications", handlers.GetAllApplications)
-			admin.PUT("/applications/:id", handlers.UpdateApplication)
-			admin.GET("/applications/stats", handlers.GetApplicationStats)
+			admin.GET("/applications", middleware.RequirePermission(models.PermissionApplicationsRead), handlers.GetAllApplications)
+			admin.PUT("/applications/:id", middleware.RequirePermission(models.PermissionApplicationsUpdate), handlers.UpdateApplication)
+			admin.GET("/applications/stats", middleware.RequirePermission(models.PermissionApplicationsRead), handlers.GetApplicationStats)
+
+			// Service catalog
+			admin.GET("/services", middleware.RequirePermission(models.PermissionServicesManage), handlers.GetAllServices)
+			admin.POST("/services", middleware.RequirePermission(models.PermissionServicesManage), handlers.CreateService)
+			admin.PUT("/services/:id", middleware.RequirePermission(models.PermissionServicesManage), handlers.UpdateService)
+			admin.DELETE("/services/:id", middleware.RequirePermission(models.PermissionServicesManage), handlers.DeleteService)
+
+			// Payments
+			admin.GET("/payments", middleware.RequirePermission(models.PermissionPaymentsRead), handlers.GetPayments)
+			admin.GET("/payments/reconciliation", middleware.RequirePermission(models.PermissionPaymentsRead), handlers.GetPaymentReconciliation)
+
+			// Refunds
+			admin.GET("/refunds", middleware.RequirePermission(models.PermissionPaymentsRead), handlers.GetRefunds)
+			admin.POST("/refunds", middleware.RequirePermission(models.PermissionPaymentsRefund), handlers.CreateStaffRefundRequest)
+			admin.POST("/refunds/:id/approve", middleware.RequirePermission(models.PermissionPaymentsRefund), handlers.ApproveRefund)
+			admin.POST("/refunds/:id/reject", middleware.RequirePermission(models.PermissionPaymentsRefund), handlers.RejectRefund)
+
+			// Invoices
+			admin.GET("/invoices", middleware.RequirePermission(models.PermissionPaymentsRead), handlers.GetInvoices)
+			admin.POST("/invoices", middleware.RequirePermission(models.PermissionInvoicesIssue), handlers.CreateInvoice)
+			admin.GET("/invoices/:id/download", middleware.RequirePermission(models.PermissionPaymentsRead), handlers.DownloadInvoice)
+
+			// Audit log
+			admin.GET("/audit", middleware.RequirePermission(models.PermissionAuditRead), handlers.GetAuditLogs)
+			admin.GET("/audit/export", middleware.RequirePermission(models.PermissionAuditRead), handlers.ExportAuditLogs)
 		}
 	}
 
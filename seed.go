@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+	"bharat-seva-space/utils"
+
+	"gorm.io/gorm"
+)
+
+// demoPassword is the password of every seeded demo user
+const demoPassword = "password123"
+
+// demoUsers are the accounts created by `seed`
+var demoUsers = []models.User{
+	{Email: "rahul.sharma@example.com", Phone: "9876500001", Name: "Rahul Sharma"},
+	{Email: "priya.patel@example.com", Phone: "9876500002", Name: "Priya Patel"},
+	{Email: "amit.kumar@example.com", Phone: "9876500003", Name: "Amit Kumar"},
+}
+
+// demoQueries are the website queries created by `seed`
+var demoQueries = []models.Query{
+	{Name: "Sunita Rao", Email: "sunita.rao@example.com", Phone: "9876500011", Service: "GST Registration", Message: "I want to register my bakery for GST.", Status: models.QueryStatusNew},
+	{Name: "Vikram Singh", Email: "vikram.singh@example.com", Phone: "9876500012", Service: "Income Tax Filing", Message: "Need help filing ITR-2 for this year.", Status: models.QueryStatusContacted},
+	{Name: "Neha Gupta", Email: "neha.gupta@example.com", Phone: "9876500013", Service: "Company Registration", Message: "Looking to register a private limited company.", Status: models.QueryStatusConverted},
+}
+
+// demoApplications are created for each demo user by `seed`, priced from the service with the same name
+var demoApplications = []models.Application{
+	{ServiceType: "GST Registration", Status: models.ApplicationStatusPending, Progress: "0%", PaymentStatus: models.PaymentStatusPending, Description: "GST registration for a proprietorship",
+		FormData: map[string]interface{}{"pan": "ABCPS1234K", "business_name": "Sharma Traders", "constitution": "proprietorship", "state": "Maharashtra", "annual_turnover": 2500000}},
+	{ServiceType: "Income Tax Filing", Status: models.ApplicationStatusInProgress, Progress: "50%", PaymentStatus: models.PaymentStatusPaid, Description: "ITR-1 filing",
+		FormData: map[string]interface{}{"pan": "ABCPS1234K", "assessment_year": "2025-26", "income_sources": "Salary", "has_foreign_income": false}},
+}
+
+// runSeed inserts demo data. Existing demo users are left untouched, so it is safe to run twice.
+func runSeed(args []string) {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	force := flags.Bool("force", false, "allow seeding when ENV=production")
+	flags.Parse(args)
+
+	if config.GetServerConfig()["env"] == "production" && !*force {
+		log.Fatal("Refusing to seed demo data with ENV=production (use --force to override)")
+	}
+
+	hashedPassword, err := utils.HashPassword(demoPassword)
+	if err != nil {
+		log.Fatal("Error hashing password:", err)
+	}
+
+	connectDB()
+
+	created := 0
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, demoUser := range demoUsers {
+			var count int64
+			tx.Model(&models.User{}).Where("email = ? OR phone = ?", demoUser.Email, demoUser.Phone).Count(&count)
+			if count > 0 {
+				continue
+			}
+
+			now := time.Now()
+			user := demoUser
+			user.Password = hashedPassword
+			user.Role = models.RoleUser
+			user.IsActive = true
+			user.EmailVerifiedAt = &now
+			user.PhoneVerifiedAt = &now
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+
+			for _, demoApplication := range demoApplications {
+				application := demoApplication
+				application.UserID = user.ID
+				var service models.Service
+				if err := tx.Where("name = ?", application.ServiceType).First(&service).Error; err != nil {
+					return fmt.Errorf("service %q not found: %v", application.ServiceType, err)
+				}
+				application.ServiceID = &service.ID
+				application.Amount = service.TotalPrice()
+				application.GSTRate = service.GSTRate
+				if err := tx.Create(&application).Error; err != nil {
+					return err
+				}
+			}
+			created++
+		}
+
+		// Queries are only seeded alongside a fresh set of users
+		if created == 0 {
+			return nil
+		}
+		for _, demoQuery := range demoQueries {
+			query := demoQuery
+			if err := tx.Create(&query).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Error seeding demo data:", err)
+	}
+
+	if created == 0 {
+		fmt.Println("Demo data already present, nothing to do")
+		return
+	}
+	fmt.Printf("Seeded %d demo users (password %q) with applications, and %d queries\n", created, demoPassword, len(demoQueries))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"bharat-seva-space/config"
+	"bharat-seva-space/database"
+	"bharat-seva-space/mailer"
+	"bharat-seva-space/payments"
+	"bharat-seva-space/routes"
+	"bharat-seva-space/sms"
+	"bharat-seva-space/storage"
+)
+
+// runServe starts the API server
+func runServe(args []string) {
+	serverConfig := config.GetServerConfig()
+
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	port := flags.String("port", serverConfig["port"], "port to listen on")
+	migrate := flags.Bool("migrate", serverConfig["auto_migrate"] == "true", "run migrations before starting")
+	flags.Parse(args)
+
+	if *port == "" {
+		*port = "8080"
+	}
+
+	// Initialize database
+	connectDB()
+
+	if *migrate {
+		if err := database.Migrate(); err != nil {
+			log.Fatal("Error migrating database:", err)
+		}
+	}
+
+	// Refuse to serve against a schema that is missing migrations
+	if err := database.CheckSchema(); err != nil {
+		log.Fatal("Error checking database schema:", err)
+	}
+
+	// Initialize document storage
+	if err := storage.InitStorage(); err != nil {
+		log.Fatal("Error initializing storage:", err)
+	}
+
+	// Initialize mailer
+	if err := mailer.InitMailer(); err != nil {
+		log.Fatal("Error initializing mailer:", err)
+	}
+
+	// Initialize SMS sender
+	if err := sms.InitSMS(); err != nil {
+		log.Fatal("Error initializing SMS sender:", err)
+	}
+
+	// Initialize payment gateway
+	if err := payments.InitPayments(); err != nil {
+		log.Fatal("Error initializing payment gateway:", err)
+	}
+
+	// Create the first admin account if none exists
+	if err := database.BootstrapAdmin(); err != nil {
+		log.Fatal("Error creating admin user:", err)
+	}
+
+	// Setup routes
+	router := routes.SetupRoutes()
+
+	log.Printf("Server starting on port %s", *port)
+	if err := router.Run(":" + *port); err != nil {
+		log.Fatal("Error starting server:", err)
+	}
+}
@@ -0,0 +1,45 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// FakeSender records messages instead of sending them. Messages are written
+// to the application log, or appended to a file when a path is configured.
+// Intended for local development and testing.
+type FakeSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFakeSender creates a fake SMS sender
+func NewFakeSender(path string) *FakeSender {
+	return &FakeSender{path: path}
+}
+
+// Send records the message
+func (s *FakeSender) Send(ctx context.Context, to, message string) error {
+	entry := fmt.Sprintf("%s to=%s %s\n", time.Now().Format(time.RFC3339), to, message)
+
+	if s.path == "" {
+		log.Printf("SMS (not sent): %s", entry)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open sms log: %v", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
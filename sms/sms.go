@@ -0,0 +1,30 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+
+	"bharat-seva-space/config"
+)
+
+// Sender delivers SMS messages
+type Sender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// Default is the SMS sender used by the application
+var Default Sender
+
+// InitSMS initializes the SMS sender selected by SMS_BACKEND
+func InitSMS() error {
+	smsConfig := config.GetSMSConfig()
+
+	switch smsConfig["backend"] {
+	case "", "fake":
+		Default = NewFakeSender(smsConfig["log_path"])
+	default:
+		return fmt.Errorf("unknown sms backend: %s", smsConfig["backend"])
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"bharat-seva-space/config"
+)
+
+// ErrNotFound is returned when an object does not exist in the backend
+var ErrNotFound = errors.New("object not found")
+
+// Storage is a backend capable of persisting document files by key.
+// Keys are slash-separated relative paths such as "12/3f9a_invoice.pdf".
+type Storage interface {
+	// Save writes size bytes from r under key
+	Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns a reader for the object stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Store is the storage backend used by the application
+var Store Storage
+
+// InitStorage initializes the storage backend selected by STORAGE_BACKEND
+func InitStorage() error {
+	storageConfig := config.GetStorageConfig()
+
+	switch storageConfig["backend"] {
+	case "", "local":
+		uploadPath := config.GetUploadConfig()["upload_path"].(string)
+		if uploadPath == "" {
+			uploadPath = "./uploads"
+		}
+		Store = NewLocalStorage(uploadPath)
+	case "s3":
+		s3, err := NewS3Storage(S3Config{
+			Endpoint:     storageConfig["s3_endpoint"],
+			Region:       storageConfig["s3_region"],
+			Bucket:       storageConfig["s3_bucket"],
+			AccessKey:    storageConfig["s3_access_key"],
+			SecretKey:    storageConfig["s3_secret_key"],
+			UsePathStyle: storageConfig["s3_use_path_style"] == "true",
+		})
+		if err != nil {
+			return err
+		}
+		Store = s3
+	default:
+		return fmt.Errorf("unknown storage backend: %s", storageConfig["backend"])
+	}
+
+	return nil
+}
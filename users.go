@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"bharat-seva-space/database"
+	"bharat-seva-space/models"
+)
+
+const userUsage = `usage: bharat-seva user <command> [options]
+
+Commands:
+  list [--role ROLE]                    List user accounts
+  set-role --email EMAIL --role ROLE    Change a user's role
+  activate --email EMAIL                Reactivate an account
+  deactivate --email EMAIL              Deactivate an account and revoke its sessions
+  unlock --email EMAIL                  Clear a login lockout
+`
+
+// runUser handles the `user` subcommand. Changes take effect in running servers
+// once their user cache expires (USER_CACHE_TTL).
+func runUser(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, userUsage)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "list":
+		userList(args[1:])
+	case "set-role":
+		userSetRole(args[1:])
+	case "activate":
+		userSetActive(args[1:], true)
+	case "deactivate":
+		userSetActive(args[1:], false)
+	case "unlock":
+		userUnlock(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown user command %q\n\n%s", args[0], userUsage)
+		os.Exit(2)
+	}
+}
+
+// parseEmailFlag parses a command's flags and returns the required --email value
+func parseEmailFlag(flags *flag.FlagSet, args []string) string {
+	email := flags.String("email", "", "email address of the user (required)")
+	flags.Parse(args)
+	if *email == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	return *email
+}
+
+// findUserByEmail loads a user by email, exiting if there is none
+func findUserByEmail(email string) models.User {
+	var user models.User
+	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		log.Fatalf("User %s not found", email)
+	}
+	return user
+}
+
+// userList prints user accounts
+func userList(args []string) {
+	flags := flag.NewFlagSet("user list", flag.ExitOnError)
+	role := flags.String("role", "", "only list users with this role")
+	flags.Parse(args)
+
+	connectDB()
+
+	query := database.DB.Order("id")
+	if *role != "" {
+		query = query.Where("role = ?", *role)
+	}
+
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		log.Fatal("Error listing users:", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tPHONE\tNAME\tROLE\tACTIVE\t2FA\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%t\t%t\t%s\n",
+			user.ID, user.Email, user.Phone, user.Name, user.Role, user.IsActive, user.TOTPEnabled,
+			user.CreatedAt.Format("2006-01-02"))
+	}
+	w.Flush()
+}
+
+// userSetRole changes a user's role
+func userSetRole(args []string) {
+	flags := flag.NewFlagSet("user set-role", flag.ExitOnError)
+	role := flags.String("role", "", "name of the new role, e.g. user, admin, support (required)")
+	email := parseEmailFlag(flags, args)
+
+	if *role == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	connectDB()
+	user := findUserByEmail(email)
+
+	var count int64
+	database.DB.Model(&models.Role{}).Where("name = ?", *role).Count(&count)
+	if count == 0 {
+		log.Fatalf("Role %s does not exist", *role)
+	}
+
+	if err := database.DB.Model(&user).Update("role", *role).Error; err != nil {
+		log.Fatal("Error updating role:", err)
+	}
+	fmt.Printf("%s is now %s\n", user.Email, *role)
+}
+
+// userSetActive activates or deactivates an account; deactivation also revokes its sessions
+func userSetActive(args []string, active bool) {
+	name := "user activate"
+	if !active {
+		name = "user deactivate"
+	}
+	email := parseEmailFlag(flag.NewFlagSet(name, flag.ExitOnError), args)
+
+	connectDB()
+	user := findUserByEmail(email)
+
+	if err := database.DB.Model(&user).Update("is_active", active).Error; err != nil {
+		log.Fatal("Error updating account:", err)
+	}
+
+	if !active {
+		if err := database.DB.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			log.Fatal("Error revoking sessions:", err)
+		}
+		fmt.Printf("%s deactivated and signed out\n", user.Email)
+		return
+	}
+	fmt.Printf("%s activated\n", user.Email)
+}
+
+// userUnlock clears a user's failed login counter and lockout
+func userUnlock(args []string) {
+	email := parseEmailFlag(flag.NewFlagSet("user unlock", flag.ExitOnError), args)
+
+	connectDB()
+	user := findUserByEmail(email)
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error; err != nil {
+		log.Fatal("Error clearing lockout:", err)
+	}
+	fmt.Printf("%s unlocked\n", user.Email)
+}
@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -11,11 +15,20 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Restricted token scopes. Tokens with a scope only grant access to specific endpoints.
+const (
+	ScopePasswordChange   = "password_change"    // may only change the password
+	ScopeTwoFactorSetup   = "two_factor_setup"   // may only enroll in two-factor authentication
+	ScopeTwoFactorPending = "two_factor_pending" // may only be exchanged for tokens with a TOTP code
+)
+
 // Claims represents JWT claims
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID uint   `json:"sid,omitempty"`
+	Scope     string `json:"scope,omitempty"` // empty for full access tokens
 	jwt.RegisteredClaims
 }
 
@@ -31,27 +44,79 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
-// GenerateToken generates a JWT token for a user
-func GenerateToken(user models.User) (string, error) {
+// GenerateToken generates a short-lived JWT access token for a user session
+func GenerateToken(user models.User, sessionID uint) (string, error) {
 	jwtConfig := config.GetJWTConfig()
 	expiry, err := time.ParseDuration(jwtConfig["expiry"])
 	if err != nil {
-		expiry = 24 * time.Hour // default to 24 hours
+		expiry = 15 * time.Minute // default to 15 minutes
+	}
+
+	claims := Claims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
 	}
 
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtConfig["secret"]))
+}
+
+// GenerateScopedToken generates a short-lived restricted token for a single purpose
+func GenerateScopedToken(user models.User, scope string) (string, error) {
 	claims := Claims{
 		UserID: user.ID,
 		Email:  user.Email,
 		Role:   user.Role,
+		Scope:  scope,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(10 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtConfig["secret"]))
+	return token.SignedString([]byte(config.GetJWTConfig()["secret"]))
+}
+
+// GenerateRandomToken generates a random opaque token for refresh tokens and one-time links
+func GenerateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateRandomPassword generates a random password for accounts created without one
+func GenerateRandomPassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 hash of an opaque token for storage
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RefreshTokenExpiry returns how long refresh tokens remain valid
+func RefreshTokenExpiry() time.Duration {
+	expiry, err := time.ParseDuration(config.GetJWTConfig()["refresh_expiry"])
+	if err != nil {
+		expiry = 30 * 24 * time.Hour // default to 30 days
+	}
+	return expiry
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -72,19 +137,3 @@ func ValidateToken(tokenString string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
-
-// ExtractUserFromToken extracts user information from JWT token
-func ExtractUserFromToken(tokenString string) (*models.User, error) {
-	claims, err := ValidateToken(tokenString)
-	if err != nil {
-		return nil, err
-	}
-
-	user := &models.User{
-		ID:    claims.UserID,
-		Email: claims.Email,
-		Role:  claims.Role,
-	}
-
-	return user, nil
-} 
\ No newline at end of file
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bharat-seva-space/config"
+)
+
+// downloadSigningKey returns the key used to sign download URLs.
+// Falls back to JWT_SECRET when DOWNLOAD_URL_SECRET is not set.
+func downloadSigningKey() []byte {
+	downloadConfig := config.GetDownloadConfig()
+	if downloadConfig["secret"] != "" {
+		return []byte(downloadConfig["secret"])
+	}
+	return []byte(config.GetJWTConfig()["secret"])
+}
+
+// downloadSignature computes the HMAC signature for a document and expiry time
+func downloadSignature(documentID uint, expires int64) string {
+	mac := hmac.New(sha256.New, downloadSigningKey())
+	mac.Write([]byte(fmt.Sprintf("document:%d:%d", documentID, expires)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignedDownloadURL returns a time-limited download URL for a document and its expiry time
+func SignedDownloadURL(documentID uint) (string, time.Time) {
+	downloadConfig := config.GetDownloadConfig()
+	expiry, err := time.ParseDuration(downloadConfig["expiry"])
+	if err != nil {
+		expiry = 15 * time.Minute // default to 15 minutes
+	}
+
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	url := fmt.Sprintf("%s/api/documents/%d/download?expires=%d&signature=%s",
+		downloadConfig["base_url"], documentID, expires, downloadSignature(documentID, expires))
+
+	return url, expiresAt
+}
+
+// VerifyDownloadSignature checks that a download signature is valid and not expired
+func VerifyDownloadSignature(documentID uint, expires, signature string) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	expected := downloadSignature(documentID, expiresAt)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimitWindow tracks the number of events for a key in the current window
+type rateLimitWindow struct {
+	count int
+	start time.Time
+}
+
+// RateLimiter allows up to limit events per key in each fixed time window.
+// State is kept in memory, so limits apply per process.
+type RateLimiter struct {
+	limit   int
+	window  time.Duration
+	mu      sync.Mutex
+	entries map[string]*rateLimitWindow
+}
+
+// NewRateLimiter creates a rate limiter allowing limit events per window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		entries: make(map[string]*rateLimitWindow),
+	}
+}
+
+// Allow records an event for key and reports whether it is within the limit
+func (l *RateLimiter) Allow(key string) bool {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop expired windows occasionally so memory stays bounded
+	if len(l.entries) >= 10000 {
+		for k, w := range l.entries {
+			if now.Sub(w.start) >= l.window {
+				delete(l.entries, k)
+			}
+		}
+	}
+
+	w, ok := l.entries[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		l.entries[key] = &rateLimitWindow{count: 1, start: now}
+		return true
+	}
+
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	return true
+}